@@ -0,0 +1,187 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A Command is a git command line with its aliases expanded.
+type Command struct {
+	// Argv is the command and its arguments. For a shell alias it holds
+	// only the arguments passed to Shell.
+	Argv []string
+
+	// Shell is the command of a "!" shell alias, which git runs with
+	// "sh -c" and Argv appended.
+	Shell string
+
+	// Aliases are the aliases that were expanded, in order.
+	Aliases []string
+}
+
+// builtins are the git commands that aliases cannot shadow.
+var builtins = map[string]bool{
+	"add": true, "am": true, "annotate": true, "apply": true, "archive": true,
+	"bisect": true, "blame": true, "branch": true, "bugreport": true,
+	"bundle": true, "cat-file": true, "check-attr": true, "check-ignore": true,
+	"check-mailmap": true, "check-ref-format": true, "checkout": true,
+	"checkout-index": true, "cherry": true, "cherry-pick": true, "clean": true,
+	"clone": true, "column": true, "commit": true, "commit-graph": true,
+	"commit-tree": true, "config": true, "count-objects": true,
+	"credential": true, "credential-cache": true, "credential-store": true,
+	"describe": true, "diagnose": true, "diff": true, "diff-files": true,
+	"diff-index": true, "diff-tree": true, "difftool": true,
+	"fast-export": true, "fast-import": true, "fetch": true,
+	"fetch-pack": true, "fmt-merge-msg": true, "for-each-ref": true,
+	"for-each-repo": true, "format-patch": true, "fsck": true,
+	"fsmonitor--daemon": true, "gc": true, "get-tar-commit-id": true,
+	"grep": true, "hash-object": true, "help": true, "hook": true,
+	"index-pack": true, "init": true, "init-db": true,
+	"interpret-trailers": true, "log": true, "ls-files": true,
+	"ls-remote": true, "ls-tree": true, "mailinfo": true, "mailsplit": true,
+	"maintenance": true, "merge": true, "merge-base": true,
+	"merge-file": true, "merge-index": true, "merge-ours": true,
+	"merge-recursive": true, "merge-tree": true, "mktag": true,
+	"mktree": true, "multi-pack-index": true, "mv": true,
+	"name-rev": true, "notes": true, "pack-objects": true,
+	"pack-redundant": true, "pack-refs": true, "patch-id": true,
+	"prune": true, "prune-packed": true, "pull": true, "push": true,
+	"range-diff": true, "read-tree": true, "rebase": true,
+	"receive-pack": true, "reflog": true, "refs": true, "remote": true,
+	"remote-ext": true, "remote-fd": true, "repack": true, "replace": true,
+	"replay": true, "rerere": true, "reset": true, "restore": true,
+	"rev-list": true, "rev-parse": true, "revert": true, "rm": true,
+	"send-pack": true, "shortlog": true, "show": true, "show-branch": true,
+	"show-index": true, "show-ref": true, "sparse-checkout": true,
+	"stage": true, "stash": true, "status": true, "stripspace": true,
+	"submodule--helper": true, "switch": true, "symbolic-ref": true,
+	"tag": true, "unpack-file": true, "unpack-objects": true,
+	"update-index": true, "update-ref": true, "update-server-info": true,
+	"upload-archive": true, "upload-pack": true, "var": true,
+	"verify-commit": true, "verify-pack": true, "verify-tag": true,
+	"version": true, "whatchanged": true, "worktree": true,
+	"write-tree": true,
+}
+
+// ExpandAlias expands the aliases of a git command line, such as
+// []string{"lol", "-5"}, the way git does: alias.<name> values are split
+// with git's quoting rules and replace the command, the remaining
+// arguments are appended, and the result is expanded again until it
+// names a built-in command or a command that is not an alias. Built-in
+// commands are never expanded, and a "!" alias ends the expansion with a
+// shell command. Commands found as git-<name> programs on the PATH, which
+// git prefers to aliases, are not looked for.
+func (c *Config) ExpandAlias(argv []string) (*Command, error) {
+	if len(argv) == 0 {
+		return nil, errors.New("no command")
+	}
+
+	cmd := &Command{Argv: append([]string(nil), argv...)}
+	for !builtins[cmd.Argv[0]] {
+		name := cmd.Argv[0]
+		for i, seen := range cmd.Aliases {
+			if seen != name {
+				continue
+			}
+
+			var b strings.Builder
+			for j, alias := range cmd.Aliases {
+				b.WriteString("\n  " + alias)
+				if j == i {
+					b.WriteString(" <==")
+				} else if j == len(cmd.Aliases)-1 {
+					b.WriteString(" ==>")
+				}
+			}
+			return nil, fmt.Errorf("alias loop detected: expansion of '%s' does not terminate:%s", cmd.Aliases[0], b.String())
+		}
+
+		value, ok := c.Get("alias." + name)
+		if !ok {
+			break
+		}
+		cmd.Aliases = append(cmd.Aliases, name)
+
+		if shell, ok := strings.CutPrefix(value, "!"); ok {
+			cmd.Shell, cmd.Argv = shell, cmd.Argv[1:]
+			return cmd, nil
+		}
+
+		expansion, err := splitCmdline(value)
+		if err != nil {
+			return nil, fmt.Errorf("bad alias.%s string: %v", name, err)
+		}
+		if expansion, err = stripAliasOptions(name, expansion); err != nil {
+			return nil, err
+		}
+		if len(expansion) == 0 {
+			return nil, fmt.Errorf("empty alias for %s", name)
+		}
+		if expansion[0] == name {
+			return nil, fmt.Errorf("recursive alias: %s", name)
+		}
+		cmd.Argv = append(expansion, cmd.Argv[1:]...)
+	}
+	return cmd, nil
+}
+
+// stripAliasOptions removes the leading pager options of an alias, which
+// git applies to itself. Other options change the environment of the
+// commands that follow, which git refuses.
+func stripAliasOptions(name string, argv []string) ([]string, error) {
+	for len(argv) > 0 && strings.HasPrefix(argv[0], "-") {
+		switch argv[0] {
+		case "-p", "--paginate", "-P", "--no-pager":
+			argv = argv[1:]
+		default:
+			return nil, fmt.Errorf("alias '%s' changes environment variables.\nYou can use '!git' in the alias to do this", name)
+		}
+	}
+	return argv, nil
+}
+
+// splitCmdline splits an alias into arguments like git's split_cmdline:
+// at unquoted whitespace, with single quotes preserving everything up to
+// the next single quote, and double quotes and backslashes escaping
+// characters.
+func splitCmdline(cmdline string) ([]string, error) {
+	var (
+		args   []string
+		arg    strings.Builder
+		quoted byte
+	)
+	for i := 0; i < len(cmdline); i++ {
+		c := cmdline[i]
+		switch {
+		case quoted == 0 && isCmdlineSpace(c):
+			args = append(args, arg.String())
+			arg.Reset()
+			for i+1 < len(cmdline) && isCmdlineSpace(cmdline[i+1]) {
+				i++
+			}
+		case quoted == 0 && (c == '\'' || c == '"'):
+			quoted = c
+		case c == quoted:
+			quoted = 0
+		default:
+			if c == '\\' && quoted != '\'' {
+				if i++; i == len(cmdline) {
+					return nil, errors.New("cmdline ends with \\")
+				}
+				c = cmdline[i]
+			}
+			arg.WriteByte(c)
+		}
+	}
+	if quoted != 0 {
+		return nil, errors.New("unclosed quote")
+	}
+	return append(args, arg.String()), nil
+}
+
+// isCmdlineSpace is git's isspace: the ASCII space, tab, newline and
+// carriage return.
+func isCmdlineSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,118 @@
+package gitconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCmdline(t *testing.T) {
+	tests := []struct {
+		cmdline string
+		want    []string
+		err     string
+	}{
+		{cmdline: "commit -S", want: []string{"commit", "-S"}},
+		{cmdline: "log  --graph\t--oneline", want: []string{"log", "--graph", "--oneline"}},
+		{cmdline: `log --format='%h %s'`, want: []string{"log", "--format=%h %s"}},
+		{cmdline: `grep "a \"b\" c"`, want: []string{"grep", `a "b" c`}},
+		{cmdline: `grep 'a\b'`, want: []string{"grep", `a\b`}},
+		{cmdline: `grep a\ b`, want: []string{"grep", "a b"}},
+		{cmdline: `x ''`, want: []string{"x", ""}},
+		{cmdline: `x \`, err: `cmdline ends with \`},
+		{cmdline: `x 'y`, err: "unclosed quote"},
+	}
+
+	for _, test := range tests {
+		got, err := splitCmdline(test.cmdline)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%q: want error %q, got %v", test.cmdline, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.cmdline, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("%q: want %q, got %q", test.cmdline, test.want, got)
+		}
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	sections, err := Parse([]byte(`[alias]
+	c = commit -S
+	lol = log --graph --decorate --pretty=oneline --abbrev-commit
+	lola = lol --all
+	ign = ls-files -o -i --exclude-standard
+	st = !git status --short
+	sst = st -b
+	log = log --oneline
+	pl = --no-pager lol
+	env = -c user.name=x commit
+	a = b
+	b = c2
+	c2 = a
+	self = self -v
+	empty = --no-pager
+	bad = \"x
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{Layers: []*Layer{{Sections: sections}}}
+
+	tests := []struct {
+		argv []string
+		want *Command
+		err  string
+	}{
+		{
+			argv: []string{"c", "-m", "msg"},
+			want: &Command{Argv: []string{"commit", "-S", "-m", "msg"}, Aliases: []string{"c"}},
+		},
+		{
+			argv: []string{"lola", "-5"},
+			want: &Command{Argv: []string{"log", "--graph", "--decorate", "--pretty=oneline", "--abbrev-commit", "--all", "-5"}, Aliases: []string{"lola", "lol"}},
+		},
+		{
+			argv: []string{"sst", "."},
+			want: &Command{Shell: "git status --short", Argv: []string{"-b", "."}, Aliases: []string{"sst", "st"}},
+		},
+		{
+			argv: []string{"log", "-1"},
+			want: &Command{Argv: []string{"log", "-1"}},
+		},
+		{
+			argv: []string{"pl"},
+			want: &Command{Argv: []string{"log", "--graph", "--decorate", "--pretty=oneline", "--abbrev-commit"}, Aliases: []string{"pl", "lol"}},
+		},
+		{
+			argv: []string{"frobnicate"},
+			want: &Command{Argv: []string{"frobnicate"}},
+		},
+		{argv: []string{"a"}, err: "alias loop detected: expansion of 'a' does not terminate:\n  a <==\n  b\n  c2 ==>"},
+		{argv: []string{"self"}, err: "recursive alias: self"},
+		{argv: []string{"empty"}, err: "empty alias for empty"},
+		{argv: []string{"bad"}, err: "bad alias.bad string: unclosed quote"},
+		{argv: []string{"env"}, err: "alias 'env' changes environment variables.\nYou can use '!git' in the alias to do this"},
+	}
+
+	for _, test := range tests {
+		got, err := conf.ExpandAlias(test.argv)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%q: want error %q, got %v", test.argv, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.argv, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("%q: want %+v, got %+v", test.argv, test.want, got)
+		}
+	}
+}
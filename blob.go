@@ -0,0 +1,175 @@
+package gitconfig
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// refRules are the places a short ref name is looked for, in order.
+var refRules = []string{
+	"%s",
+	"refs/%s",
+	"refs/tags/%s",
+	"refs/heads/%s",
+	"refs/remotes/%s",
+	"refs/remotes/%s/HEAD",
+}
+
+// LoadBlob reads the config file stored in the repository object named by
+// rev, like "git config --blob". rev is an object name, a ref such as
+// "HEAD", "main" or "refs/tags/v1", or "<rev>:<path>" for a file in the
+// tree of a commit, such as "HEAD:.gitmodules". Loose objects, packfiles
+// and alternates are read, but abbreviated object names and revision
+// suffixes like "~1" are not supported. Includes are followed, except
+// for relative paths, which git only allows in files.
+func (l *Loader) LoadBlob(rev string) (*Layer, error) {
+	if l.GitDir == "" {
+		return nil, errors.New("not in a git directory")
+	}
+
+	store, err := l.objectStore()
+	if err != nil {
+		return nil, err
+	}
+	defer store.close()
+
+	id, err := l.resolveRevision(store, rev)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve config blob '%s': %w", rev, err)
+	}
+	typ, data, err := store.readObject(id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve config blob '%s': %w", rev, err)
+	}
+	if typ != objBlob {
+		return nil, fmt.Errorf("reference '%s' does not point to a blob", rev)
+	}
+
+	sections, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("bad config blob '%s': %w", rev, err)
+	}
+
+	ll := *l
+	ll.source, ll.remoteURLs = "", nil
+	if sections, err = ll.include(sections, "", nil); err != nil {
+		return nil, err
+	}
+	return &Layer{
+		Scope:    ScopeCommand,
+		Sections: sections,
+	}, nil
+}
+
+// resolveRevision returns the object name of rev.
+func (l *Loader) resolveRevision(store *objectStore, rev string) (string, error) {
+	name, path, hasPath := strings.Cut(rev, ":")
+	if hasPath && name == "" {
+		return "", errors.New("reading from the index is not supported")
+	}
+
+	id, err := l.resolveName(store, name)
+	if err != nil || !hasPath {
+		return id, err
+	}
+
+	if id, err = peelToTree(store, id); err != nil {
+		return "", err
+	}
+	for _, part := range strings.Split(path, "/") {
+		if part == "" {
+			continue
+		}
+
+		typ, data, err := store.readObject(id)
+		if err != nil {
+			return "", err
+		}
+		if typ != objTree {
+			return "", fmt.Errorf("path '%s' does not exist in '%s'", path, name)
+		}
+		if id, err = findTreeEntry(data, part, store.hashSize); err != nil {
+			return "", fmt.Errorf("path '%s' does not exist in '%s'", path, name)
+		}
+	}
+	return id, nil
+}
+
+// resolveName returns the object name of a full object name or ref.
+func (l *Loader) resolveName(store *objectStore, name string) (string, error) {
+	if len(name) == store.hashSize*2 {
+		if _, err := hex.DecodeString(name); err == nil {
+			return strings.ToLower(name), nil
+		}
+	}
+
+	for _, rule := range refRules {
+		id, ok, err := l.resolveRef(fmt.Sprintf(rule, name))
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("unknown revision '%s'", name)
+}
+
+// peelToTree follows tags and commits to the tree they point to.
+func peelToTree(store *objectStore, id string) (string, error) {
+	for {
+		typ, data, err := store.readObject(id)
+		if err != nil {
+			return "", err
+		}
+
+		switch typ {
+		case objTree:
+			return id, nil
+		case objCommit:
+			return headerField(data, "tree")
+		case objTag:
+			if id, err = headerField(data, "object"); err != nil {
+				return "", err
+			}
+		default:
+			return "", fmt.Errorf("object %s is not a tree", id)
+		}
+	}
+}
+
+// headerField returns the value of the first "<name> <value>" header line
+// of a commit or tag.
+func headerField(data []byte, name string) (string, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, name+" "); ok {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("missing %s header", name)
+}
+
+// findTreeEntry returns the object name of the entry called name in a tree,
+// made of "<mode> <name>\x00<binary object name>" entries.
+func findTreeEntry(tree []byte, name string, hashSize int) (string, error) {
+	for len(tree) > 0 {
+		sp := bytes.IndexByte(tree, ' ')
+		nul := bytes.IndexByte(tree, 0)
+		if sp < 0 || nul < sp || nul+1+hashSize > len(tree) {
+			return "", errors.New("corrupt tree")
+		}
+
+		entry, id := string(tree[sp+1:nul]), tree[nul+1:nul+1+hashSize]
+		if entry == name {
+			return hex.EncodeToString(id), nil
+		}
+		tree = tree[nul+1+hashSize:]
+	}
+	return "", fmt.Errorf("%s not found", name)
+}
@@ -0,0 +1,319 @@
+package gitconfig
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func objectID(typ string, data []byte) string {
+	sum := sha1.Sum(append([]byte(fmt.Sprintf("%s %d\x00", typ, len(data))), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// writeLoose writes a loose object and returns its name.
+func writeLoose(t *testing.T, objects, typ string, data []byte) string {
+	t.Helper()
+
+	id := objectID(typ, data)
+	path := filepath.Join(objects, id[:2], id[2:])
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	raw := append([]byte(fmt.Sprintf("%s %d\x00", typ, len(data))), data...)
+	if err := os.WriteFile(path, deflate(t, raw), 0444); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// A packEntry is an object of a test packfile, stored whole or as a delta
+// against an earlier entry.
+type packEntry struct {
+	typ  int
+	data []byte
+	base int // index of the delta base, or -1
+	ref  bool
+}
+
+// writePack writes a packfile and its version 2 index, and returns the
+// names of the objects.
+func writePack(t *testing.T, objects string, entries []packEntry) []string {
+	t.Helper()
+
+	typeNames := map[int]string{objCommit: "commit", objTree: "tree", objBlob: "blob", objTag: "tag"}
+
+	var pack bytes.Buffer
+	pack.WriteString("PACK")
+	binary.Write(&pack, binary.BigEndian, uint32(2))
+	binary.Write(&pack, binary.BigEndian, uint32(len(entries)))
+
+	ids := make([]string, len(entries))
+	offsets := make([]int, len(entries))
+	for i, e := range entries {
+		ids[i] = objectID(typeNames[e.typ], e.data)
+		offsets[i] = pack.Len()
+
+		typ, payload := e.typ, e.data
+		if e.base >= 0 {
+			typ, payload = objOfsDelta, makeDelta(entries[e.base].data, e.data)
+			if e.ref {
+				typ = objRefDelta
+			}
+		}
+
+		size := len(payload)
+		c := byte(typ<<4) | byte(size&15)
+		for size >>= 4; size > 0; size >>= 7 {
+			pack.WriteByte(c | 0x80)
+			c = byte(size & 0x7f)
+		}
+		pack.WriteByte(c)
+
+		switch {
+		case e.base >= 0 && e.ref:
+			id, _ := hex.DecodeString(ids[e.base])
+			pack.Write(id)
+		case e.base >= 0:
+			rel := offsets[i] - offsets[e.base]
+			enc := []byte{byte(rel & 0x7f)}
+			for rel >>= 7; rel > 0; rel >>= 7 {
+				rel--
+				enc = append([]byte{0x80 | byte(rel&0x7f)}, enc...)
+			}
+			pack.Write(enc)
+		}
+		pack.Write(deflate(t, payload))
+	}
+	sum := sha1.Sum(pack.Bytes())
+	pack.Write(sum[:])
+
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return ids[order[i]] < ids[order[j]] })
+
+	var idx bytes.Buffer
+	idx.WriteString("\377tOc")
+	binary.Write(&idx, binary.BigEndian, uint32(2))
+	for b := 0; b < 256; b++ {
+		n := 0
+		for _, id := range ids {
+			if first, _ := hex.DecodeString(id[:2]); int(first[0]) <= b {
+				n++
+			}
+		}
+		binary.Write(&idx, binary.BigEndian, uint32(n))
+	}
+	for _, i := range order {
+		id, _ := hex.DecodeString(ids[i])
+		idx.Write(id)
+	}
+	idx.Write(make([]byte, 4*len(entries))) // CRCs
+	for _, i := range order {
+		binary.Write(&idx, binary.BigEndian, uint32(offsets[i]))
+	}
+	idx.Write(sum[:])
+
+	name := filepath.Join(objects, "pack", "pack-"+hex.EncodeToString(sum[:]))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name+".pack", pack.Bytes(), 0444); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name+".idx", idx.Bytes(), 0444); err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+// makeDelta returns a delta that copies the common prefix of base and
+// target and inserts the rest.
+func makeDelta(base, target []byte) []byte {
+	size := func(n int) []byte {
+		var enc []byte
+		for ; n >= 0x80; n >>= 7 {
+			enc = append(enc, byte(n&0x7f)|0x80)
+		}
+		return append(enc, byte(n))
+	}
+
+	delta := append(size(len(base)), size(len(target))...)
+	n := 0
+	for n < len(base) && n < len(target) && n < 0xff && base[n] == target[n] {
+		n++
+	}
+	if n > 0 {
+		delta = append(delta, 0x80|0x10, byte(n))
+	}
+	for rest := target[n:]; len(rest) > 0; {
+		chunk := rest
+		if len(chunk) > 0x7f {
+			chunk = chunk[:0x7f]
+		}
+		delta = append(delta, byte(len(chunk)))
+		delta = append(delta, chunk...)
+		rest = rest[len(chunk):]
+	}
+	return delta
+}
+
+func treeEntry(mode, name, id string) string {
+	raw, _ := hex.DecodeString(id)
+	return mode + " " + name + "\x00" + string(raw)
+}
+
+func TestLoadBlob(t *testing.T) {
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+	writeGitDir(t, gitDir)
+	objects := filepath.Join(gitDir, "objects")
+
+	const (
+		modules = "[submodule \"lib\"]\n\tpath = lib\n\turl = https://example.com/lib.git\n"
+		lfs     = "[lfs]\n\turl = https://example.com/lfs\n"
+	)
+	packed := writePack(t, objects, []packEntry{
+		{typ: objBlob, data: []byte(lfs), base: -1},
+		{typ: objBlob, data: []byte(lfs + "\tlocksverify = true\n"), base: 0},
+		{typ: objBlob, data: []byte(lfs + "[include]\n\tpath = /nonexistent\n"), base: 0, ref: true},
+	})
+
+	modulesID := writeLoose(t, objects, "blob", []byte(modules))
+	sub := writeLoose(t, objects, "tree", []byte(treeEntry("100644", "config", packed[1])))
+	tree := writeLoose(t, objects, "tree", []byte(
+		treeEntry("100644", ".gitmodules", modulesID)+
+			treeEntry("100644", ".lfsconfig", packed[0])+
+			treeEntry("40000", "etc", sub)))
+	commit := writeLoose(t, objects, "commit", []byte("tree "+tree+"\nauthor A <a@example.com> 0 +0000\n\ninitial\n"))
+	tag := writeLoose(t, objects, "tag", []byte("object "+commit+"\ntype commit\ntag v1\n\nv1\n"))
+
+	writeFiles(t, gitDir, map[string]string{
+		"refs/heads/main":   commit + "\n",
+		"refs/heads/config": commit + "\n",
+		"config":            "[core]\n\trepositoryformatversion = 0\n",
+		"packed-refs":       "# pack-refs with: peeled fully-peeled sorted\n" + tag + " refs/tags/v1\n^" + commit + "\n",
+	})
+
+	tests := []struct {
+		rev, key, want string
+	}{
+		{"HEAD:.gitmodules", "submodule.lib.url", "https://example.com/lib.git"},
+		{"config:.gitmodules", "submodule.lib.url", "https://example.com/lib.git"},
+		{"main:.lfsconfig", "lfs.url", "https://example.com/lfs"},
+		{"v1:etc/config", "lfs.locksverify", "true"},
+		{commit + ":/etc/config", "lfs.locksverify", "true"},
+		{modulesID, "submodule.lib.path", "lib"},
+		{packed[2], "include.path", "/nonexistent"},
+	}
+
+	l := &Loader{GitDir: gitDir, Env: testEnv(nil)}
+	for _, test := range tests {
+		layer, err := l.LoadBlob(test.rev)
+		if err != nil {
+			t.Errorf("%s: %v", test.rev, err)
+			continue
+		}
+		if got, _ := (&Config{Layers: []*Layer{layer}}).Get(test.key); got != test.want {
+			t.Errorf("%s: want %s = %q, got %q", test.rev, test.key, test.want, got)
+		}
+	}
+
+	l.FS = noPackReads{OSFileSystem}
+	layer, err := l.LoadBlob(packed[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := (&Config{Layers: []*Layer{layer}}).Get("lfs.locksverify"); got != "true" {
+		t.Errorf("want packed blob read in place, got lfs.locksverify = %q", got)
+	}
+	l.FS = nil
+
+	errs := []struct {
+		rev, want string
+	}{
+		{"HEAD", "reference 'HEAD' does not point to a blob"},
+		{"HEAD:missing", "unable to resolve config blob 'HEAD:missing'"},
+		{"nope:.gitmodules", "unable to resolve config blob 'nope:.gitmodules'"},
+	}
+	for _, test := range errs {
+		if _, err := l.LoadBlob(test.rev); err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("%s: want error %q, got %v", test.rev, test.want, err)
+		}
+	}
+}
+
+// noPackReads is a FileSystem that packfiles can only be opened from, not
+// read whole.
+type noPackReads struct{ FileSystem }
+
+func (f noPackReads) ReadFile(name string) ([]byte, error) {
+	if strings.HasSuffix(name, ".pack") {
+		return nil, fmt.Errorf("read whole packfile %s", name)
+	}
+	return f.FileSystem.ReadFile(name)
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("the quick brown fox")
+	delta := []byte{
+		19, 21, // sizes
+		0x80 | 0x01 | 0x10, 4, 5, // copy "quick"
+		1, ' ',
+		0x80 | 0x10, 3, // copy "the"
+		0x80 | 0x01 | 0x10, 15, 4, // copy " fox"
+		0x80 | 0x01 | 0x10, 9, 7, // copy " brown "
+		1, '!',
+	}
+	got, err := applyDelta(base, delta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "quick the fox brown !"; string(got) != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestApplyDeltaCorrupt(t *testing.T) {
+	tests := []struct {
+		name        string
+		base, delta string
+	}{
+		{"huge result", "a", "\x01\xdc\xff\xff\xff\x7f"},
+		{"overflowing size", "a", "\x01\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01"},
+		{"largest int size", "a", "\x01\xff\xff\xff\xff\xff\xff\xff\xff\x7f"},
+		{"result larger than delta", "a", "\x01\x80\x01\x01x"},
+		{"result past its size", "abc", "\x03\x01\x91\x00\x03"},
+		{"short result", "abc", "\x03\x03\x91\x00\x01"},
+	}
+
+	for _, test := range tests {
+		if got, err := applyDelta([]byte(test.base), []byte(test.delta)); err == nil {
+			t.Errorf("%s: want error, got %q", test.name, got)
+		}
+	}
+}
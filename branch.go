@@ -0,0 +1,245 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A Branch is the configuration of a local branch, read from its
+// [branch "<name>"] sections.
+type Branch struct {
+	Name string
+
+	// Remote is the remote fetched from, or "." for the local
+	// repository. PushRemote overrides it for pushes.
+	Remote     string
+	PushRemote string
+
+	// Merge are the refs on Remote that the branch merges, the first of
+	// which is its upstream.
+	Merge []string
+
+	// Rebase is "true", "false", "merges" or "interactive", or empty if
+	// unset, in which case pull.rebase applies.
+	Rebase string
+
+	Description string
+}
+
+// Branches returns every configured branch, in the order they first
+// appear.
+func (c *Config) Branches() ([]*Branch, error) {
+	var (
+		branches []*Branch
+		byName   = make(map[string]*Branch)
+	)
+	for _, s := range c.Sections() {
+		if s.Type != "branch" || s.ID == "" {
+			continue
+		}
+
+		b, ok := byName[s.ID]
+		if !ok {
+			b = &Branch{Name: s.ID}
+			byName[s.ID] = b
+			branches = append(branches, b)
+		}
+		if err := b.set(s); err != nil {
+			return nil, err
+		}
+	}
+	return branches, nil
+}
+
+// Branch returns the configuration of the branch called name, which may
+// be given as "main" or "refs/heads/main". A branch without any
+// configuration has only its Name set.
+func (c *Config) Branch(name string) (*Branch, error) {
+	name = strings.TrimPrefix(name, "refs/heads/")
+
+	branches, err := c.Branches()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range branches {
+		if b.Name == name {
+			return b, nil
+		}
+	}
+	return &Branch{Name: name}, nil
+}
+
+// set applies the values of a [branch] section to b.
+func (b *Branch) set(s *Section) error {
+	for name, value := range s.Values {
+		switch name {
+		case "remote":
+			b.Remote = value
+		case "pushremote":
+			b.PushRemote = value
+		case "merge":
+			b.Merge = append(b.Merge, value)
+		case "description":
+			b.Description = value
+		case "rebase":
+			rebase, err := parseRebase("branch."+b.Name+".rebase", value)
+			if err != nil {
+				return err
+			}
+			b.Rebase = rebase
+		}
+	}
+	return nil
+}
+
+// parseRebase normalizes a branch.<name>.rebase or pull.rebase value.
+func parseRebase(key, value string) (string, error) {
+	if b, err := ParseBool(value); err == nil {
+		return fmt.Sprint(b), nil
+	}
+
+	switch value {
+	case "merges", "m":
+		return "merges", nil
+	case "interactive", "i":
+		return "interactive", nil
+	case "preserve", "p":
+		return "", fmt.Errorf("%s: 'preserve' superseded by 'merges'", value)
+	}
+	return "", fmt.Errorf("invalid value for '%s': '%s'", key, value)
+}
+
+// Upstream returns the remote-tracking ref of the upstream of the branch
+// called name, what "<name>@{upstream}" refers to. It is the first
+// branch.<name>.merge ref, mapped through the fetch refspecs of
+// branch.<name>.remote, or itself if the remote is ".".
+func (c *Config) Upstream(name string) (string, error) {
+	b, err := c.Branch(name)
+	if err != nil {
+		return "", err
+	}
+	return c.upstream(b)
+}
+
+func (c *Config) upstream(b *Branch) (string, error) {
+	if b.Remote == "" || len(b.Merge) == 0 {
+		return "", fmt.Errorf("no upstream configured for branch '%s'", b.Name)
+	}
+
+	merge := b.Merge[0]
+	if b.Remote == "." {
+		if !strings.HasPrefix(merge, "refs/") {
+			merge = "refs/heads/" + merge
+		}
+		return merge, nil
+	}
+
+	tracking, err := c.tracking(b.Remote, merge)
+	if err != nil {
+		return "", err
+	}
+	if tracking == "" {
+		return "", fmt.Errorf("upstream branch '%s' not stored as a remote-tracking branch", merge)
+	}
+	return tracking, nil
+}
+
+// PushRef returns the remote-tracking ref of where the branch called name is
+// pushed to, what "<name>@{push}" refers to. The remote is
+// branch.<name>.pushRemote, remote.pushDefault or branch.<name>.remote,
+// and the remote ref is chosen by its push refspecs, or else by
+// push.default.
+func (c *Config) PushRef(name string) (string, error) {
+	b, err := c.Branch(name)
+	if err != nil {
+		return "", err
+	}
+	ref := "refs/heads/" + b.Name
+
+	remoteName := b.PushRemote
+	if remoteName == "" {
+		remoteName, _ = c.Get("remote.pushdefault")
+	}
+	if remoteName == "" {
+		remoteName = b.Remote
+	}
+	if remoteName == "" {
+		remoteName = "origin"
+	}
+
+	remote, err := c.Remote(remoteName)
+	if err != nil {
+		return "", err
+	}
+	if remote == nil {
+		remote = &Remote{Name: remoteName}
+	}
+
+	if len(remote.Push) > 0 {
+		push, err := remote.PushRefSpecs()
+		if err != nil {
+			return "", err
+		}
+		dst, ok := push.Dest(ref)
+		if !ok {
+			return "", fmt.Errorf("push refspecs for '%s' do not include '%s'", remoteName, b.Name)
+		}
+		return c.pushTracking(remoteName, dst)
+	}
+	if remote.Mirror {
+		return c.pushTracking(remoteName, ref)
+	}
+
+	pushDefault, _ := c.Get("push.default")
+	switch pushDefault {
+	case "nothing":
+		return "", errors.New("push has no destination (push.default is 'nothing')")
+	case "matching", "current":
+		return c.pushTracking(remoteName, ref)
+	case "upstream", "tracking":
+		return c.upstream(b)
+	case "", "simple":
+		up, err := c.upstream(b)
+		if err != nil {
+			return "", err
+		}
+		cur, err := c.pushTracking(remoteName, ref)
+		if err != nil {
+			return "", err
+		}
+		if cur != up {
+			return "", errors.New("cannot resolve 'simple' push to a single destination")
+		}
+		return cur, nil
+	}
+	return "", fmt.Errorf("malformed value for push.default: %s", pushDefault)
+}
+
+// tracking maps the ref of the remote called name to its remote-tracking
+// ref. It returns the empty string if the fetch refspecs do not store ref.
+func (c *Config) tracking(name, ref string) (string, error) {
+	remote, err := c.Remote(name)
+	if err != nil || remote == nil {
+		return "", err
+	}
+
+	fetch, err := remote.FetchRefSpecs()
+	if err != nil {
+		return "", err
+	}
+	dst, _ := fetch.Dest(ref)
+	return dst, nil
+}
+
+// pushTracking returns the remote-tracking ref of the ref pushed to.
+func (c *Config) pushTracking(name, ref string) (string, error) {
+	tracking, err := c.tracking(name, ref)
+	if err != nil {
+		return "", err
+	}
+	if tracking == "" {
+		return "", fmt.Errorf("push destination '%s' on remote '%s' has no local tracking branch", ref, name)
+	}
+	return tracking, nil
+}
@@ -0,0 +1,118 @@
+package gitconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBranches(t *testing.T) {
+	sections, err := Parse([]byte(`[branch "main"]
+	remote = origin
+	merge = refs/heads/main
+	rebase = merges
+	description = The main line.
+[branch "topic"]
+	remote = .
+	merge = refs/heads/main
+	pushRemote = fork
+	rebase = yes
+[branch "main"]
+	merge = refs/heads/next
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{Layers: []*Layer{{Sections: sections}}}
+
+	branches, err := conf.Branches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Branch{
+		{Name: "main", Remote: "origin", Merge: []string{"refs/heads/main", "refs/heads/next"}, Rebase: "merges", Description: "The main line."},
+		{Name: "topic", Remote: ".", PushRemote: "fork", Merge: []string{"refs/heads/main"}, Rebase: "true"},
+	}
+	if !reflect.DeepEqual(want, branches) {
+		t.Errorf("want branches %+v, got %+v", want, branches)
+	}
+
+	b, err := conf.Branch("refs/heads/other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&Branch{Name: "other"}); !reflect.DeepEqual(want, b) {
+		t.Errorf("want %+v, got %+v", want, b)
+	}
+
+	sections, _ = Parse([]byte("[branch \"main\"]\n\trebase = preserve\n"))
+	if _, err := (&Config{Layers: []*Layer{{Sections: sections}}}).Branches(); err == nil {
+		t.Error("want error for rebase = preserve")
+	}
+}
+
+func TestUpstreamAndPush(t *testing.T) {
+	const base = `[remote "origin"]
+	url = https://example.com/repo.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+[remote "fork"]
+	url = https://example.com/fork.git
+	fetch = +refs/heads/*:refs/remotes/fork/*
+[remote "bare"]
+	url = https://example.com/bare.git
+[branch "main"]
+	remote = origin
+	merge = refs/heads/main
+[branch "topic"]
+	remote = origin
+	merge = refs/heads/main
+[branch "local"]
+	remote = .
+	merge = main
+[branch "untracked"]
+	remote = bare
+	merge = refs/heads/untracked
+`
+
+	tests := []struct {
+		extra, branch string
+		upstream      string
+		push          string
+	}{
+		{"", "main", "refs/remotes/origin/main", "refs/remotes/origin/main"},
+		{"", "topic", "refs/remotes/origin/main", "error: cannot resolve 'simple' push to a single destination"},
+		{"[push]\n\tdefault = current\n", "topic", "refs/remotes/origin/main", "refs/remotes/origin/topic"},
+		{"[push]\n\tdefault = upstream\n", "topic", "refs/remotes/origin/main", "refs/remotes/origin/main"},
+		{"[push]\n\tdefault = nothing\n", "main", "refs/remotes/origin/main", "error: push has no destination (push.default is 'nothing')"},
+		{"[push]\n\tdefault = bogus\n", "main", "refs/remotes/origin/main", "error: malformed value for push.default: bogus"},
+		{"[remote]\n\tpushDefault = fork\n[push]\n\tdefault = current\n", "topic", "refs/remotes/origin/main", "refs/remotes/fork/topic"},
+		{"[branch \"topic\"]\n\tpushRemote = fork\n[push]\n\tdefault = current\n", "topic", "refs/remotes/origin/main", "refs/remotes/fork/topic"},
+		{"[remote \"origin\"]\n\tpush = refs/heads/*:refs/heads/review/*\n", "topic", "refs/remotes/origin/main", "refs/remotes/origin/review/topic"},
+		{"[remote \"origin\"]\n\tpush = refs/heads/main\n", "topic", "refs/remotes/origin/main", "error: push refspecs for 'origin' do not include 'topic'"},
+		{"", "local", "refs/heads/main", "error: push destination 'refs/heads/local' on remote '.' has no local tracking branch"},
+		{"", "untracked", "error: upstream branch 'refs/heads/untracked' not stored as a remote-tracking branch", "error: upstream branch 'refs/heads/untracked' not stored as a remote-tracking branch"},
+		{"", "none", "error: no upstream configured for branch 'none'", "error: no upstream configured for branch 'none'"},
+	}
+
+	for _, test := range tests {
+		sections, err := Parse([]byte(base + test.extra))
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf := &Config{Layers: []*Layer{{Sections: sections}}}
+
+		check := func(what, want string, got string, err error) {
+			if err != nil {
+				got = "error: " + err.Error()
+			}
+			if got != want {
+				t.Errorf("%s %s with %q: want %q, got %q", test.branch, what, strings.TrimSpace(test.extra), want, got)
+			}
+		}
+
+		up, err := conf.Upstream(test.branch)
+		check("upstream", test.upstream, up, err)
+		push, err := conf.PushRef(test.branch)
+		check("push", test.push, push, err)
+	}
+}
@@ -0,0 +1,93 @@
+// Command gitconfigfmt formats git config files.
+//
+// Usage:
+//
+//	gitconfigfmt [flags] [path ...]
+//
+// Without paths, it formats standard input to standard output. With -l,
+// it lists the files that are not formatted and exits with status 1 if
+// there are any, which makes it usable as a pre-commit check.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	gitconfig "github.com/benburkert/go-gitconfig"
+)
+
+var (
+	list   = flag.Bool("l", false, "list files whose formatting differs from gitconfigfmt's")
+	write  = flag.Bool("w", false, "write result to (source) file instead of stdout")
+	sorted = flag.Bool("s", false, "sort sections and keys")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gitconfigfmt [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	opts := gitconfig.FormatOptions{Sort: *sorted}
+
+	if flag.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fatal(err)
+		}
+		out, err := gitconfig.Format(src, opts)
+		if err != nil {
+			fatal(fmt.Errorf("<standard input>: %v", err))
+		}
+		os.Stdout.Write(out)
+		return
+	}
+
+	status := 0
+	for _, name := range flag.Args() {
+		changed, err := formatFile(name, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gitconfigfmt: %v\n", err)
+			status = 2
+			continue
+		}
+		if changed && *list && status == 0 {
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+func formatFile(name string, opts gitconfig.FormatOptions) (bool, error) {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return false, err
+	}
+	out, err := gitconfig.Format(src, opts)
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", name, err)
+	}
+
+	changed := !bytes.Equal(src, out)
+	if changed && *list {
+		fmt.Println(name)
+	}
+	if changed && *write {
+		if err := gitconfig.WriteFile(name, out, 0644); err != nil {
+			return changed, err
+		}
+	}
+	if !*list && !*write {
+		os.Stdout.Write(out)
+	}
+	return changed, nil
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "gitconfigfmt: %v\n", err)
+	os.Exit(2)
+}
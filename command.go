@@ -0,0 +1,218 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// AddParameter adds a "git -c" override to the command scope. The
+// parameter is a "key=value" pair, where "key" alone sets key to true and
+// "key=" sets it to the empty string.
+func (l *Loader) AddParameter(param string) error {
+	layer := &Layer{}
+	if err := layer.addParameter(param); err != nil {
+		return err
+	}
+	l.parameters = append(l.parameters, layer.Sections...)
+	return nil
+}
+
+// AddConfigEnv adds a "git --config-env" override to the command scope.
+// The spec is a "key=ENVVAR" pair that sets key to the value of the
+// environment variable ENVVAR, which must exist.
+func (l *Loader) AddConfigEnv(spec string) error {
+	i := strings.LastIndexByte(spec, '=')
+	if i < 0 {
+		return fmt.Errorf("invalid config format: %s", spec)
+	}
+
+	key, name := spec[:i], spec[i+1:]
+	if name == "" {
+		return fmt.Errorf("missing environment variable name for configuration '%s'", key)
+	}
+	value, ok := l.lookupEnv(name)
+	if !ok {
+		return fmt.Errorf("missing environment variable '%s' for configuration '%s'", name, key)
+	}
+
+	layer := &Layer{}
+	if err := layer.add(key, value); err != nil {
+		return err
+	}
+	l.parameters = append(l.parameters, layer.Sections...)
+	return nil
+}
+
+// commandLayer returns the command scope: first the GIT_CONFIG_COUNT,
+// GIT_CONFIG_KEY_<n> and GIT_CONFIG_VALUE_<n> variables, then the
+// GIT_CONFIG_PARAMETERS list written by "git -c", and last the overrides
+// added to the Loader.
+func (l *Loader) commandLayer() (*Layer, error) {
+	layer := &Layer{Scope: ScopeCommand}
+
+	if env, ok := l.lookupEnv("GIT_CONFIG_COUNT"); ok {
+		count, err := parseConfigCount(env)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < count; i++ {
+			keyVar, valueVar := fmt.Sprintf("GIT_CONFIG_KEY_%d", i), fmt.Sprintf("GIT_CONFIG_VALUE_%d", i)
+
+			key, ok := l.lookupEnv(keyVar)
+			if !ok {
+				return nil, fmt.Errorf("missing config key %s", keyVar)
+			}
+			value, ok := l.lookupEnv(valueVar)
+			if !ok {
+				return nil, fmt.Errorf("missing config value %s", valueVar)
+			}
+
+			if err := layer.add(key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if env, ok := l.lookupEnv("GIT_CONFIG_PARAMETERS"); ok {
+		if err := layer.parseParameters(env); err != nil {
+			return nil, err
+		}
+	}
+
+	layer.Sections = append(layer.Sections, l.parameters...)
+
+	sections, err := l.include(layer.Sections, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	layer.Sections = sections
+	return layer, nil
+}
+
+func parseConfigCount(env string) (int, error) {
+	if env == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(env, 10, 64)
+	if err != nil {
+		if nerr, ok := err.(*strconv.NumError); ok && nerr.Err == strconv.ErrRange {
+			return 0, errors.New("too many entries in GIT_CONFIG_COUNT")
+		}
+		return 0, errors.New("bogus count in GIT_CONFIG_COUNT")
+	}
+	if n < 0 || n > math.MaxInt32 {
+		return 0, errors.New("too many entries in GIT_CONFIG_COUNT")
+	}
+	return int(n), nil
+}
+
+// parseParameters parses the GIT_CONFIG_PARAMETERS format: a whitespace
+// separated list of single quoted 'key=value' or 'key'='value' entries.
+func (layer *Layer) parseParameters(env string) error {
+	bogus := errors.New("bogus format in GIT_CONFIG_PARAMETERS")
+
+	cur := strings.TrimLeft(env, " \t\n")
+	for cur != "" {
+		key, rest, ok := sqDequoteStep(cur)
+		if !ok {
+			return bogus
+		}
+
+		switch {
+		case rest == "" || isSpace(rest[0]):
+			// old style 'key=value'
+			if err := layer.addParameter(key); err != nil {
+				return err
+			}
+		case rest[0] == '=':
+			// new style 'key'='value'
+			rest = rest[1:]
+
+			var value string
+			switch {
+			case rest != "" && rest[0] == '\'':
+				if value, rest, ok = sqDequoteStep(rest); !ok || (rest != "" && !isSpace(rest[0])) {
+					return bogus
+				}
+			case rest == "" || isSpace(rest[0]):
+				// implicit boolean 'key'=
+				value = "true"
+			default:
+				return bogus
+			}
+
+			if err := layer.add(key, value); err != nil {
+				return err
+			}
+		default:
+			return bogus
+		}
+
+		cur = strings.TrimLeft(rest, " \t\n")
+	}
+	return nil
+}
+
+// addParameter adds a "git -c" style "key=value" parameter. A key without
+// "=" is a boolean true, and "key=" is the empty string.
+func (layer *Layer) addParameter(param string) error {
+	key, value, ok := strings.Cut(param, "=")
+	if !ok {
+		value = "true"
+	}
+
+	if key = strings.TrimSpace(key); key == "" {
+		return fmt.Errorf("bogus config parameter: %s", param)
+	}
+	return layer.add(key, value)
+}
+
+// add validates key and adds it to the layer as a section of its own.
+func (layer *Layer) add(key, value string) error {
+	stype, id, name, err := parseKey(key)
+	if err != nil {
+		return err
+	}
+
+	layer.Sections = append(layer.Sections, &Section{
+		Type:   stype,
+		ID:     id,
+		Values: map[string]string{name: value},
+	})
+	return nil
+}
+
+// sqDequoteStep removes the shell single quoting from the start of s,
+// where a quote is escaped by closing the quotes around \' and an
+// exclamation mark by closing them around \!. It returns the unquoted
+// word and the rest of s.
+func sqDequoteStep(s string) (word, rest string, ok bool) {
+	if s == "" || s[0] != '\'' {
+		return "", "", false
+	}
+
+	var buf strings.Builder
+	for i := 1; i < len(s); i++ {
+		if c := s[i]; c != '\'' {
+			buf.WriteByte(c)
+			continue
+		}
+
+		if i+3 < len(s) && s[i+1] == '\\' && (s[i+2] == '\'' || s[i+2] == '!') && s[i+3] == '\'' {
+			buf.WriteByte(s[i+2])
+			i += 3
+			continue
+		}
+		return buf.String(), s[i+1:], true
+	}
+	return "", "", false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
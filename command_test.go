@@ -0,0 +1,253 @@
+package gitconfig
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func TestLoadEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"etc/gitconfig":   "[core]\n\teditor = ed\n",
+		"system.config":   "[core]\n\teditor = emacs\n",
+		"global.config":   "[user]\n\tname = CI\n",
+		"home/.gitconfig": "[user]\n\tname = Home\n",
+		".git/config":     "[user]\n\temail = local@example.com\n",
+	})
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{"HOME": filepath.Join(dir, "home")},
+			want: map[string]string{"core.editor": "ed", "user.name": "Home"},
+		},
+		{
+			name: "GIT_CONFIG_SYSTEM and GIT_CONFIG_GLOBAL",
+			env: map[string]string{
+				"HOME":              filepath.Join(dir, "home"),
+				"GIT_CONFIG_SYSTEM": filepath.Join(dir, "system.config"),
+				"GIT_CONFIG_GLOBAL": filepath.Join(dir, "global.config"),
+			},
+			want: map[string]string{"core.editor": "emacs", "user.name": "CI"},
+		},
+		{
+			name: "GIT_CONFIG_NOSYSTEM",
+			env: map[string]string{
+				"HOME":                filepath.Join(dir, "home"),
+				"GIT_CONFIG_SYSTEM":   filepath.Join(dir, "system.config"),
+				"GIT_CONFIG_NOSYSTEM": "1",
+			},
+			want: map[string]string{"core.editor": "", "user.name": "Home"},
+		},
+		{
+			name: "command scope",
+			env: map[string]string{
+				"GIT_CONFIG_COUNT":      "2",
+				"GIT_CONFIG_KEY_0":      "user.email",
+				"GIT_CONFIG_VALUE_0":    "count@example.com",
+				"GIT_CONFIG_KEY_1":      "Remote.Origin.URL",
+				"GIT_CONFIG_VALUE_1":    "https://example.com/repo.git",
+				"GIT_CONFIG_PARAMETERS": `'core.editor=vim' 'user.name'='It'\''s me' 'core.bare'=`,
+			},
+			want: map[string]string{
+				"core.editor":       "vim",
+				"core.bare":         "true",
+				"user.name":         "It's me",
+				"user.email":        "count@example.com",
+				"remote.Origin.url": "https://example.com/repo.git",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		l := &Loader{
+			Prefix: dir,
+			GitDir: filepath.Join(dir, ".git"),
+			Env:    testEnv(test.env),
+		}
+		conf, err := l.Load()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		for key, want := range test.want {
+			if got, _ := conf.Get(key); want != got {
+				t.Errorf("%s: want %s = %q, got %q", test.name, key, want, got)
+			}
+		}
+	}
+}
+
+func TestLoadEnvironmentErrors(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want string
+	}{
+		{
+			env:  map[string]string{"GIT_CONFIG_COUNT": "two"},
+			want: "bogus count in GIT_CONFIG_COUNT",
+		},
+		{
+			env:  map[string]string{"GIT_CONFIG_COUNT": "99999999999"},
+			want: "too many entries in GIT_CONFIG_COUNT",
+		},
+		{
+			env:  map[string]string{"GIT_CONFIG_COUNT": "1", "GIT_CONFIG_VALUE_0": "v"},
+			want: "missing config key GIT_CONFIG_KEY_0",
+		},
+		{
+			env:  map[string]string{"GIT_CONFIG_COUNT": "1", "GIT_CONFIG_KEY_0": "a.b"},
+			want: "missing config value GIT_CONFIG_VALUE_0",
+		},
+		{
+			env:  map[string]string{"GIT_CONFIG_COUNT": "1", "GIT_CONFIG_KEY_0": "nodot", "GIT_CONFIG_VALUE_0": "v"},
+			want: "key does not contain a section: nodot",
+		},
+		{
+			env:  map[string]string{"GIT_CONFIG_PARAMETERS": "core.editor=vim"},
+			want: "bogus format in GIT_CONFIG_PARAMETERS",
+		},
+		{
+			env:  map[string]string{"GIT_CONFIG_PARAMETERS": "'core.'"},
+			want: "key does not contain variable name: core.",
+		},
+		{
+			env:  map[string]string{"GIT_CONFIG_PARAMETERS": "'core.1editor=vim'"},
+			want: "invalid key: core.1editor",
+		},
+		{
+			env:  map[string]string{"GIT_CONFIG_NOSYSTEM": "maybe"},
+			want: "bad boolean environment value 'maybe' for 'GIT_CONFIG_NOSYSTEM'",
+		},
+	}
+
+	for _, test := range tests {
+		l := &Loader{Env: testEnv(test.env)}
+		if _, err := l.Load(); err == nil {
+			t.Errorf("want error %q, got nil", test.want)
+		} else if test.want != err.Error() {
+			t.Errorf("want error %q, got %q", test.want, err)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"core.editor", []string{"core", "", "editor"}},
+		{"Remote.Origin.URL", []string{"remote", "Origin", "url"}},
+		{"url.git@github.com:.insteadOf", []string{"url", "git@github.com:", "insteadof"}},
+	}
+
+	for _, test := range tests {
+		section, subsection, name, err := parseKey(test.key)
+		if err != nil {
+			t.Errorf("%s: %v", test.key, err)
+			continue
+		}
+		if got := []string{section, subsection, name}; !reflect.DeepEqual(test.want, got) {
+			t.Errorf("%s: want %q, got %q", test.key, test.want, got)
+		}
+	}
+}
+
+func TestLoaderParameters(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".git/config": "[user]\n\tname = Local\n\temail = local@example.com\n[core]\n\tbare = true\n",
+	})
+
+	l := &Loader{
+		Prefix: dir,
+		GitDir: filepath.Join(dir, ".git"),
+		Env: testEnv(map[string]string{
+			"GIT_CONFIG_PARAMETERS": "'user.name=Env'",
+			"SIGNING_KEY":           "ABCD1234",
+		}),
+	}
+
+	for _, param := range []string{"user.name=Override", "core.bare=", "color.ui", "user.email=a=b"} {
+		if err := l.AddParameter(param); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.AddConfigEnv("user.signingKey=SIGNING_KEY"); err != nil {
+		t.Fatal(err)
+	}
+
+	conf, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := ScopeCommand, conf.Layers[len(conf.Layers)-1].Scope; want != got {
+		t.Errorf("want last layer scope %v, got %v", want, got)
+	}
+
+	values := map[string]string{
+		"user.name":       "Override",
+		"user.email":      "a=b",
+		"user.signingkey": "ABCD1234",
+		"core.bare":       "",
+		"color.ui":        "true",
+	}
+	for key, want := range values {
+		if got, _ := conf.Get(key); want != got {
+			t.Errorf("want %s = %q, got %q", key, want, got)
+		}
+	}
+
+	if want, got := []string{"Local", "Env", "Override"}, conf.GetAll("user.name"); !reflect.DeepEqual(want, got) {
+		t.Errorf("want user.name values %q, got %q", want, got)
+	}
+}
+
+func TestLoaderParameterErrors(t *testing.T) {
+	l := &Loader{Env: testEnv(map[string]string{"EMPTY": ""})}
+
+	params := map[string]string{
+		"=value":        "bogus config parameter: =value",
+		"core=value":    "key does not contain a section: core",
+		"core.=value":   "key does not contain variable name: core.",
+		"core.ed_itor=": "invalid key: core.ed_itor",
+	}
+	for param, want := range params {
+		if err := l.AddParameter(param); err == nil {
+			t.Errorf("%s: want error %q, got nil", param, want)
+		} else if want != err.Error() {
+			t.Errorf("%s: want error %q, got %q", param, want, err)
+		}
+	}
+
+	specs := map[string]string{
+		"user.name":         "invalid config format: user.name",
+		"user.name=":        "missing environment variable name for configuration 'user.name'",
+		"user.name=MISSING": "missing environment variable 'MISSING' for configuration 'user.name'",
+	}
+	for spec, want := range specs {
+		if err := l.AddConfigEnv(spec); err == nil {
+			t.Errorf("%s: want error %q, got nil", spec, want)
+		} else if want != err.Error() {
+			t.Errorf("%s: want error %q, got %q", spec, want, err)
+		}
+	}
+
+	if err := l.AddConfigEnv("user.name=EMPTY"); err != nil {
+		t.Errorf("want empty environment variable accepted, got %v", err)
+	}
+}
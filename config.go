@@ -1,24 +1,118 @@
 package gitconfig
 
+import (
+	"fmt"
+	"strings"
+)
+
 // go get github.com/pointlander/peg
 //go:generate peg -switch -inline config.peg
 
 func (p *config) addSection(stype string) {
+	stype = strings.ToLower(stype)
+
+	var id string
+	if i := strings.IndexByte(stype, '.'); i >= 0 {
+		// deprecated [section.subsection] syntax
+		stype, id = stype[:i], stype[i+1:]
+	}
+
 	p.curSection = &Section{
 		Type:   stype,
+		ID:     id,
 		Values: make(map[string]string),
 	}
 	p.sections = append(p.sections, p.curSection)
 }
 
 func (p *config) setID(id string) {
-	p.curSection.ID = id
+	p.curSection.ID = unescapeID(id)
 }
 
 func (p *config) addValue(value string) {
+	value, err := unquote(value)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("bad config line for %s.%s: %v", p.curSection.Type, p.curKey, err)
+	}
+
+	if _, ok := p.curSection.Values[p.curKey]; ok {
+		// a repeated key continues in a new section so that every value
+		// of a multi-valued key is kept in order.
+		p.curSection = &Section{
+			Type:   p.curSection.Type,
+			ID:     p.curSection.ID,
+			Values: make(map[string]string),
+		}
+		p.sections = append(p.sections, p.curSection)
+	}
 	p.curSection.Values[p.curKey] = value
 }
 
 func (p *config) setKey(key string) {
-	p.curKey = key
+	p.curKey = strings.ToLower(key)
+}
+
+// unescapeID decodes a quoted subsection name, in which a backslash
+// escapes the character that follows it.
+func unescapeID(raw string) string {
+	if !strings.Contains(raw, `\`) {
+		return raw
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '\\' && i+1 < len(raw) {
+			i++
+		}
+		buf.WriteByte(raw[i])
+	}
+	return buf.String()
+}
+
+// unquote decodes a raw value the way git does: double quotes preserve
+// whitespace and comment characters, a backslash escapes \, ", n, t or
+// b, and a backslash at the end of a line continues the value on the
+// next. Tabs outside of quotes are kept as spaces.
+func unquote(raw string) (string, error) {
+	if !strings.ContainsAny(raw, "\"\\\t") {
+		return raw, nil
+	}
+
+	var (
+		buf    strings.Builder
+		quoted bool
+	)
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; c {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if i++; i == len(raw) {
+				return "", fmt.Errorf("value ends with \\")
+			}
+			switch c = raw[i]; c {
+			case '\n', '\r':
+				// line continuation
+			case '\\', '"':
+				buf.WriteByte(c)
+			case 'n':
+				buf.WriteByte('\n')
+			case 't':
+				buf.WriteByte('\t')
+			case 'b':
+				buf.WriteByte('\b')
+			default:
+				return "", fmt.Errorf("invalid escape \\%c", c)
+			}
+		case '\t':
+			if quoted {
+				buf.WriteByte(c)
+			} else {
+				buf.WriteByte(' ')
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String(), nil
 }
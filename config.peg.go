@@ -19,16 +19,20 @@ const (
 	ruleValueLine
 	ruleValue
 	ruleIdentifier
+	ruleSubsection
 	ruleWord
+	ruleQuoted
 	ruleSpaceComment
 	ruleComment
 	ruleSpace
 	ruleEndOfLine
+	ruleLineEnd
 	rulePegText
 	ruleAction0
 	ruleAction1
 	ruleAction2
 	ruleAction3
+	ruleAction4
 
 	rulePre_
 	rule_In_
@@ -42,16 +46,20 @@ var rul3s = [...]string{
 	"ValueLine",
 	"Value",
 	"Identifier",
+	"Subsection",
 	"Word",
+	"Quoted",
 	"SpaceComment",
 	"Comment",
 	"Space",
 	"EndOfLine",
+	"LineEnd",
 	"PegText",
 	"Action0",
 	"Action1",
 	"Action2",
 	"Action3",
+	"Action4",
 
 	"Pre_",
 	"_In_",
@@ -371,10 +379,11 @@ type config struct {
 	sections   []*Section
 	curSection *Section
 	curKey     string
+	err        error
 
 	Buffer string
 	buffer []rune
-	rules  [16]func() bool
+	rules  [20]func() bool
 	Parse  func(rule ...int) error
 	Reset  func()
 	tokenTree
@@ -460,6 +469,8 @@ func (p *config) Execute() {
 			p.setKey(text)
 		case ruleAction3:
 			p.addValue(text)
+		case ruleAction4:
+			p.addValue("true")
 
 		}
 	}
@@ -527,224 +538,12 @@ func (p *config) Init() {
 
 	_rules = [...]func() bool{
 		nil,
-		/* 0 Grammar <- <(SpaceComment / Section)+> */
+		/* 0 Grammar <- <((SpaceComment / Section)* !.)> */
 		func() bool {
 			position0, tokenIndex0, depth0 := position, tokenIndex, depth
 			{
 				position1 := position
 				depth++
-				{
-					position4, tokenIndex4, depth4 := position, tokenIndex, depth
-					if !_rules[ruleSpaceComment]() {
-						goto l5
-					}
-					goto l4
-				l5:
-					position, tokenIndex, depth = position4, tokenIndex4, depth4
-					{
-						position6 := position
-						depth++
-					l7:
-						{
-							position8, tokenIndex8, depth8 := position, tokenIndex, depth
-							if !_rules[ruleSpace]() {
-								goto l8
-							}
-							goto l7
-						l8:
-							position, tokenIndex, depth = position8, tokenIndex8, depth8
-						}
-						if buffer[position] != rune('[') {
-							goto l0
-						}
-						position++
-					l9:
-						{
-							position10, tokenIndex10, depth10 := position, tokenIndex, depth
-							if !_rules[ruleSpace]() {
-								goto l10
-							}
-							goto l9
-						l10:
-							position, tokenIndex, depth = position10, tokenIndex10, depth10
-						}
-						{
-							position11 := position
-							depth++
-							if !_rules[ruleIdentifier]() {
-								goto l0
-							}
-							depth--
-							add(rulePegText, position11)
-						}
-						{
-							add(ruleAction0, position)
-						}
-						{
-							position13, tokenIndex13, depth13 := position, tokenIndex, depth
-							if !_rules[ruleSpace]() {
-								goto l13
-							}
-						l15:
-							{
-								position16, tokenIndex16, depth16 := position, tokenIndex, depth
-								if !_rules[ruleSpace]() {
-									goto l16
-								}
-								goto l15
-							l16:
-								position, tokenIndex, depth = position16, tokenIndex16, depth16
-							}
-							if buffer[position] != rune('"') {
-								goto l13
-							}
-							position++
-							{
-								position17 := position
-								depth++
-								if !_rules[ruleIdentifier]() {
-									goto l13
-								}
-								depth--
-								add(rulePegText, position17)
-							}
-							{
-								add(ruleAction1, position)
-							}
-							if buffer[position] != rune('"') {
-								goto l13
-							}
-							position++
-							goto l14
-						l13:
-							position, tokenIndex, depth = position13, tokenIndex13, depth13
-						}
-					l14:
-					l19:
-						{
-							position20, tokenIndex20, depth20 := position, tokenIndex, depth
-							if !_rules[ruleSpace]() {
-								goto l20
-							}
-							goto l19
-						l20:
-							position, tokenIndex, depth = position20, tokenIndex20, depth20
-						}
-						if buffer[position] != rune(']') {
-							goto l0
-						}
-						position++
-						if !_rules[ruleSpaceComment]() {
-							goto l0
-						}
-					l21:
-						{
-							position22, tokenIndex22, depth22 := position, tokenIndex, depth
-							{
-								position23 := position
-								depth++
-							l24:
-								{
-									position25, tokenIndex25, depth25 := position, tokenIndex, depth
-									if !_rules[ruleSpace]() {
-										goto l25
-									}
-									goto l24
-								l25:
-									position, tokenIndex, depth = position25, tokenIndex25, depth25
-								}
-								{
-									position26 := position
-									depth++
-									if !_rules[ruleIdentifier]() {
-										goto l22
-									}
-									depth--
-									add(rulePegText, position26)
-								}
-								{
-									add(ruleAction2, position)
-								}
-							l28:
-								{
-									position29, tokenIndex29, depth29 := position, tokenIndex, depth
-									if !_rules[ruleSpace]() {
-										goto l29
-									}
-									goto l28
-								l29:
-									position, tokenIndex, depth = position29, tokenIndex29, depth29
-								}
-								if buffer[position] != rune('=') {
-									goto l22
-								}
-								position++
-							l30:
-								{
-									position31, tokenIndex31, depth31 := position, tokenIndex, depth
-									if !_rules[ruleSpace]() {
-										goto l31
-									}
-									goto l30
-								l31:
-									position, tokenIndex, depth = position31, tokenIndex31, depth31
-								}
-								{
-									position32 := position
-									depth++
-									{
-										position33 := position
-										depth++
-										if !_rules[ruleWord]() {
-											goto l22
-										}
-									l34:
-										{
-											position35, tokenIndex35, depth35 := position, tokenIndex, depth
-											if !_rules[ruleSpace]() {
-												goto l35
-											}
-										l36:
-											{
-												position37, tokenIndex37, depth37 := position, tokenIndex, depth
-												if !_rules[ruleSpace]() {
-													goto l37
-												}
-												goto l36
-											l37:
-												position, tokenIndex, depth = position37, tokenIndex37, depth37
-											}
-											if !_rules[ruleWord]() {
-												goto l35
-											}
-											goto l34
-										l35:
-											position, tokenIndex, depth = position35, tokenIndex35, depth35
-										}
-										depth--
-										add(ruleValue, position33)
-									}
-									depth--
-									add(rulePegText, position32)
-								}
-								{
-									add(ruleAction3, position)
-								}
-								if !_rules[ruleSpaceComment]() {
-									goto l22
-								}
-								depth--
-								add(ruleValueLine, position23)
-							}
-							goto l21
-						l22:
-							position, tokenIndex, depth = position22, tokenIndex22, depth22
-						}
-						depth--
-						add(ruleSection, position6)
-					}
-				}
-			l4:
 			l2:
 				{
 					position3, tokenIndex3, depth3 := position, tokenIndex, depth
@@ -817,8 +616,70 @@ func (p *config) Init() {
 								{
 									position52 := position
 									depth++
-									if !_rules[ruleIdentifier]() {
-										goto l48
+									{
+										position137 := position
+										depth++
+									l138:
+										{
+											position139, tokenIndex139, depth139 := position, tokenIndex, depth
+											{
+												position140, tokenIndex140, depth140 := position, tokenIndex, depth
+												if buffer[position] != rune('\\') {
+													goto l141
+												}
+												position++
+												if !matchDot() {
+													goto l141
+												}
+												goto l140
+											l141:
+												position, tokenIndex, depth = position140, tokenIndex140, depth140
+												{
+													position142, tokenIndex142, depth142 := position, tokenIndex, depth
+													{
+														switch buffer[position] {
+														case '\n':
+															if buffer[position] != rune('\n') {
+																goto l142
+															}
+															position++
+															break
+														case '\r':
+															if buffer[position] != rune('\r') {
+																goto l142
+															}
+															position++
+															break
+														case '\\':
+															if buffer[position] != rune('\\') {
+																goto l142
+															}
+															position++
+															break
+														default:
+															if buffer[position] != rune('"') {
+																goto l142
+															}
+															position++
+															break
+														}
+													}
+
+													goto l139
+												l142:
+													position, tokenIndex, depth = position142, tokenIndex142, depth142
+												}
+												if !matchDot() {
+													goto l139
+												}
+											}
+										l140:
+											goto l138
+										l139:
+											position, tokenIndex, depth = position139, tokenIndex139, depth139
+										}
+										depth--
+										add(ruleSubsection, position137)
 									}
 									depth--
 									add(rulePegText, position52)
@@ -849,108 +710,45 @@ func (p *config) Init() {
 								goto l3
 							}
 							position++
-							if !_rules[ruleSpaceComment]() {
-								goto l3
+						l150:
+							{
+								position151, tokenIndex151, depth151 := position, tokenIndex, depth
+								if !_rules[ruleSpace]() {
+									goto l151
+								}
+								goto l150
+							l151:
+								position, tokenIndex, depth = position151, tokenIndex151, depth151
 							}
+							{
+								position152, tokenIndex152, depth152 := position, tokenIndex, depth
+								if !_rules[ruleLineEnd]() {
+									goto l153
+								}
+								goto l152
+							l153:
+								position, tokenIndex, depth = position152, tokenIndex152, depth152
+								if !_rules[ruleValueLine]() {
+									goto l3
+								}
+							}
+						l152:
 						l56:
 							{
 								position57, tokenIndex57, depth57 := position, tokenIndex, depth
 								{
-									position58 := position
-									depth++
-								l59:
-									{
-										position60, tokenIndex60, depth60 := position, tokenIndex, depth
-										if !_rules[ruleSpace]() {
-											goto l60
-										}
-										goto l59
-									l60:
-										position, tokenIndex, depth = position60, tokenIndex60, depth60
-									}
-									{
-										position61 := position
-										depth++
-										if !_rules[ruleIdentifier]() {
-											goto l57
-										}
-										depth--
-										add(rulePegText, position61)
-									}
-									{
-										add(ruleAction2, position)
-									}
-								l63:
-									{
-										position64, tokenIndex64, depth64 := position, tokenIndex, depth
-										if !_rules[ruleSpace]() {
-											goto l64
-										}
-										goto l63
-									l64:
-										position, tokenIndex, depth = position64, tokenIndex64, depth64
-									}
-									if buffer[position] != rune('=') {
-										goto l57
-									}
-									position++
-								l65:
-									{
-										position66, tokenIndex66, depth66 := position, tokenIndex, depth
-										if !_rules[ruleSpace]() {
-											goto l66
-										}
-										goto l65
-									l66:
-										position, tokenIndex, depth = position66, tokenIndex66, depth66
-									}
-									{
-										position67 := position
-										depth++
-										{
-											position68 := position
-											depth++
-											if !_rules[ruleWord]() {
-												goto l57
-											}
-										l69:
-											{
-												position70, tokenIndex70, depth70 := position, tokenIndex, depth
-												if !_rules[ruleSpace]() {
-													goto l70
-												}
-											l71:
-												{
-													position72, tokenIndex72, depth72 := position, tokenIndex, depth
-													if !_rules[ruleSpace]() {
-														goto l72
-													}
-													goto l71
-												l72:
-													position, tokenIndex, depth = position72, tokenIndex72, depth72
-												}
-												if !_rules[ruleWord]() {
-													goto l70
-												}
-												goto l69
-											l70:
-												position, tokenIndex, depth = position70, tokenIndex70, depth70
-											}
-											depth--
-											add(ruleValue, position68)
-										}
-										depth--
-										add(rulePegText, position67)
-									}
-									{
-										add(ruleAction3, position)
+									position135, tokenIndex135, depth135 := position, tokenIndex, depth
+									if !_rules[ruleValueLine]() {
+										goto l136
 									}
-									if !_rules[ruleSpaceComment]() {
+									goto l135
+								l136:
+									position, tokenIndex, depth = position135, tokenIndex135, depth135
+									if !_rules[ruleLineEnd]() {
 										goto l57
 									}
-									depth--
-									add(ruleValueLine, position58)
 								}
+							l135:
 								goto l56
 							l57:
 								position, tokenIndex, depth = position57, tokenIndex57, depth57
@@ -964,6 +762,15 @@ func (p *config) Init() {
 				l3:
 					position, tokenIndex, depth = position3, tokenIndex3, depth3
 				}
+				{
+					position4, tokenIndex4, depth4 := position, tokenIndex, depth
+					if !matchDot() {
+						goto l4
+					}
+					goto l0
+				l4:
+					position, tokenIndex, depth = position4, tokenIndex4, depth4
+				}
 				depth--
 				add(ruleGrammar, position1)
 			}
@@ -972,10 +779,129 @@ func (p *config) Init() {
 			position, tokenIndex, depth = position0, tokenIndex0, depth0
 			return false
 		},
-		/* 1 Section <- <(Space* '[' Space* <Identifier> Action0 (Space+ '"' <Identifier> Action1 '"')? Space* ']' SpaceComment ValueLine*)> */
-		nil,
-		/* 2 ValueLine <- <(Space* <Identifier> Action2 Space* '=' Space* <Value> Action3 SpaceComment)> */
+		/* 1 Section <- <(Space* '[' Space* <Identifier> Action0 (Space+ '"' <Subsection> Action1 '"')? Space* ']' Space* (LineEnd / ValueLine) (ValueLine / LineEnd)*)> */
 		nil,
+		/* 2 ValueLine <- <(Space* <Identifier> Action2 ((Space* '=' Space* <Value?> Action3) / Action4) LineEnd)> */
+		func() bool {
+			position149, tokenIndex149, depth149 := position, tokenIndex, depth
+			{
+				position58 := position
+				depth++
+			l59:
+				{
+					position60, tokenIndex60, depth60 := position, tokenIndex, depth
+					if !_rules[ruleSpace]() {
+						goto l60
+					}
+					goto l59
+				l60:
+					position, tokenIndex, depth = position60, tokenIndex60, depth60
+				}
+				{
+					position61 := position
+					depth++
+					if !_rules[ruleIdentifier]() {
+						goto l149
+					}
+					depth--
+					add(rulePegText, position61)
+				}
+				{
+					add(ruleAction2, position)
+				}
+				{
+					position147, tokenIndex147, depth147 := position, tokenIndex, depth
+				l63:
+					{
+						position64, tokenIndex64, depth64 := position, tokenIndex, depth
+						if !_rules[ruleSpace]() {
+							goto l64
+						}
+						goto l63
+					l64:
+						position, tokenIndex, depth = position64, tokenIndex64, depth64
+					}
+					if buffer[position] != rune('=') {
+						goto l148
+					}
+					position++
+				l65:
+					{
+						position66, tokenIndex66, depth66 := position, tokenIndex, depth
+						if !_rules[ruleSpace]() {
+							goto l66
+						}
+						goto l65
+					l66:
+						position, tokenIndex, depth = position66, tokenIndex66, depth66
+					}
+					{
+						position67 := position
+						depth++
+						{
+							position143, tokenIndex143, depth143 := position, tokenIndex, depth
+							{
+								position68 := position
+								depth++
+								if !_rules[ruleWord]() {
+									goto l143
+								}
+							l69:
+								{
+									position70, tokenIndex70, depth70 := position, tokenIndex, depth
+									if !_rules[ruleSpace]() {
+										goto l70
+									}
+								l71:
+									{
+										position72, tokenIndex72, depth72 := position, tokenIndex, depth
+										if !_rules[ruleSpace]() {
+											goto l72
+										}
+										goto l71
+									l72:
+										position, tokenIndex, depth = position72, tokenIndex72, depth72
+									}
+									if !_rules[ruleWord]() {
+										goto l70
+									}
+									goto l69
+								l70:
+									position, tokenIndex, depth = position70, tokenIndex70, depth70
+								}
+								depth--
+								add(ruleValue, position68)
+							}
+							goto l144
+						l143:
+							position, tokenIndex, depth = position143, tokenIndex143, depth143
+						}
+					l144:
+						depth--
+						add(rulePegText, position67)
+					}
+					{
+						add(ruleAction3, position)
+					}
+					goto l147
+				l148:
+					position, tokenIndex, depth = position147, tokenIndex147, depth147
+					{
+						add(ruleAction4, position)
+					}
+				}
+			l147:
+				if !_rules[ruleLineEnd]() {
+					goto l149
+				}
+				depth--
+				add(ruleValueLine, position58)
+			}
+			return true
+		l149:
+			position, tokenIndex, depth = position149, tokenIndex149, depth149
+			return false
+		},
 		/* 3 Value <- <(Word (Space+ Word)*)> */
 		nil,
 		/* 4 Identifier <- <((&('.') '.') | (&('@') '@') | (&('-') '-') | (&('_') '_') | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') ([0-9] / [0-9])) | (&('A' | 'B' | 'C' | 'D' | 'E' | 'F' | 'G' | 'H' | 'I' | 'J' | 'K' | 'L' | 'M' | 'N' | 'O' | 'P' | 'Q' | 'R' | 'S' | 'T' | 'U' | 'V' | 'W' | 'X' | 'Y' | 'Z') [A-Z]) | (&('a' | 'b' | 'c' | 'd' | 'e' | 'f' | 'g' | 'h' | 'i' | 'j' | 'k' | 'l' | 'm' | 'n' | 'o' | 'p' | 'q' | 'r' | 's' | 't' | 'u' | 'v' | 'w' | 'x' | 'y' | 'z') [a-z]))+> */
@@ -1115,103 +1041,329 @@ func (p *config) Init() {
 			position, tokenIndex, depth = position77, tokenIndex77, depth77
 			return false
 		},
-		/* 5 Word <- <(!((&('\n') '\n') | (&('\r') '\r') | (&('#') '#') | (&('\t') '\t') | (&(' ') ' ')) .)+> */
+		/* 5 Subsection <- <(('\\' .) / (!((&('\n') '\n') | (&('\r') '\r') | (&('\\') '\\') | (&('"') '"')) .))*> */
+		nil,
+		/* 6 Word <- <((&('"') Quoted) | ('\\' .) | (!((&('\n') '\n') | (&('\r') '\r') | (&('#') '#') | (&(';') ';') | (&('"') '"') | (&('\\') '\\') | (&('\t') '\t') | (&(' ') ' ')) .))+> */
 		func() bool {
 			position87, tokenIndex87, depth87 := position, tokenIndex, depth
 			{
 				position88 := position
 				depth++
 				{
-					position91, tokenIndex91, depth91 := position, tokenIndex, depth
+					position114, tokenIndex114, depth114 := position, tokenIndex, depth
 					{
-						switch buffer[position] {
-						case '\n':
-							if buffer[position] != rune('\n') {
-								goto l91
-							}
-							position++
-							break
-						case '\r':
-							if buffer[position] != rune('\r') {
-								goto l91
-							}
-							position++
-							break
-						case '#':
-							if buffer[position] != rune('#') {
-								goto l91
-							}
-							position++
-							break
-						case '\t':
-							if buffer[position] != rune('\t') {
-								goto l91
-							}
-							position++
-							break
-						default:
-							if buffer[position] != rune(' ') {
-								goto l91
+						position115 := position
+						depth++
+						if buffer[position] != rune('"') {
+							goto l115
+						}
+						position++
+					l116:
+						{
+							position117, tokenIndex117, depth117 := position, tokenIndex, depth
+							{
+								position118, tokenIndex118, depth118 := position, tokenIndex, depth
+								if buffer[position] != rune('\\') {
+									goto l119
+								}
+								position++
+								if !matchDot() {
+									goto l119
+								}
+								goto l118
+							l119:
+								position, tokenIndex, depth = position118, tokenIndex118, depth118
+								{
+									position120, tokenIndex120, depth120 := position, tokenIndex, depth
+									{
+										switch buffer[position] {
+										case '\n':
+											if buffer[position] != rune('\n') {
+												goto l120
+											}
+											position++
+											break
+										case '\r':
+											if buffer[position] != rune('\r') {
+												goto l120
+											}
+											position++
+											if buffer[position] != rune('\n') {
+												goto l120
+											}
+											position++
+											break
+										case '\\':
+											if buffer[position] != rune('\\') {
+												goto l120
+											}
+											position++
+											break
+										default:
+											if buffer[position] != rune('"') {
+												goto l120
+											}
+											position++
+											break
+										}
+									}
+
+									goto l117
+								l120:
+									position, tokenIndex, depth = position120, tokenIndex120, depth120
+								}
+								if !matchDot() {
+									goto l117
+								}
 							}
-							position++
-							break
+						l118:
+							goto l116
+						l117:
+							position, tokenIndex, depth = position117, tokenIndex117, depth117
+						}
+						if buffer[position] != rune('"') {
+							goto l115
 						}
+						position++
+						depth--
+						add(ruleQuoted, position115)
 					}
-
-					goto l87
-				l91:
-					position, tokenIndex, depth = position91, tokenIndex91, depth91
-				}
-				if !matchDot() {
-					goto l87
-				}
-			l89:
-				{
-					position90, tokenIndex90, depth90 := position, tokenIndex, depth
+					goto l114
+				l115:
+					position, tokenIndex, depth = position114, tokenIndex114, depth114
+					if buffer[position] != rune('\\') {
+						goto l122
+					}
+					position++
+					if !matchDot() {
+						goto l122
+					}
+					goto l114
+				l122:
+					position, tokenIndex, depth = position114, tokenIndex114, depth114
 					{
-						position93, tokenIndex93, depth93 := position, tokenIndex, depth
+						position121, tokenIndex121, depth121 := position, tokenIndex, depth
 						{
 							switch buffer[position] {
 							case '\n':
 								if buffer[position] != rune('\n') {
-									goto l93
+									goto l121
 								}
 								position++
 								break
 							case '\r':
 								if buffer[position] != rune('\r') {
-									goto l93
+									goto l121
 								}
 								position++
 								break
 							case '#':
 								if buffer[position] != rune('#') {
-									goto l93
+									goto l121
+								}
+								position++
+								break
+							case ';':
+								if buffer[position] != rune(';') {
+									goto l121
+								}
+								position++
+								break
+							case '"':
+								if buffer[position] != rune('"') {
+									goto l121
+								}
+								position++
+								break
+							case '\\':
+								if buffer[position] != rune('\\') {
+									goto l121
 								}
 								position++
 								break
 							case '\t':
 								if buffer[position] != rune('\t') {
-									goto l93
+									goto l121
 								}
 								position++
 								break
 							default:
 								if buffer[position] != rune(' ') {
-									goto l93
+									goto l121
 								}
 								position++
 								break
 							}
 						}
 
-						goto l90
-					l93:
-						position, tokenIndex, depth = position93, tokenIndex93, depth93
+						goto l87
+					l121:
+						position, tokenIndex, depth = position121, tokenIndex121, depth121
 					}
 					if !matchDot() {
-						goto l90
+						goto l87
 					}
+				}
+			l114:
+			l89:
+				{
+					position90, tokenIndex90, depth90 := position, tokenIndex, depth
+					{
+						position123, tokenIndex123, depth123 := position, tokenIndex, depth
+						{
+							position124 := position
+							depth++
+							if buffer[position] != rune('"') {
+								goto l124
+							}
+							position++
+						l125:
+							{
+								position126, tokenIndex126, depth126 := position, tokenIndex, depth
+								{
+									position127, tokenIndex127, depth127 := position, tokenIndex, depth
+									if buffer[position] != rune('\\') {
+										goto l128
+									}
+									position++
+									if !matchDot() {
+										goto l128
+									}
+									goto l127
+								l128:
+									position, tokenIndex, depth = position127, tokenIndex127, depth127
+									{
+										position129, tokenIndex129, depth129 := position, tokenIndex, depth
+										{
+											switch buffer[position] {
+											case '\n':
+												if buffer[position] != rune('\n') {
+													goto l129
+												}
+												position++
+												break
+											case '\r':
+												if buffer[position] != rune('\r') {
+													goto l129
+												}
+												position++
+												if buffer[position] != rune('\n') {
+													goto l129
+												}
+												position++
+												break
+											case '\\':
+												if buffer[position] != rune('\\') {
+													goto l129
+												}
+												position++
+												break
+											default:
+												if buffer[position] != rune('"') {
+													goto l129
+												}
+												position++
+												break
+											}
+										}
+
+										goto l126
+									l129:
+										position, tokenIndex, depth = position129, tokenIndex129, depth129
+									}
+									if !matchDot() {
+										goto l126
+									}
+								}
+							l127:
+								goto l125
+							l126:
+								position, tokenIndex, depth = position126, tokenIndex126, depth126
+							}
+							if buffer[position] != rune('"') {
+								goto l124
+							}
+							position++
+							depth--
+							add(ruleQuoted, position124)
+						}
+						goto l123
+					l124:
+						position, tokenIndex, depth = position123, tokenIndex123, depth123
+						if buffer[position] != rune('\\') {
+							goto l131
+						}
+						position++
+						if !matchDot() {
+							goto l131
+						}
+						goto l123
+					l131:
+						position, tokenIndex, depth = position123, tokenIndex123, depth123
+						{
+							position130, tokenIndex130, depth130 := position, tokenIndex, depth
+							{
+								switch buffer[position] {
+								case '\n':
+									if buffer[position] != rune('\n') {
+										goto l130
+									}
+									position++
+									break
+								case '\r':
+									if buffer[position] != rune('\r') {
+										goto l130
+									}
+									position++
+									break
+								case '#':
+									if buffer[position] != rune('#') {
+										goto l130
+									}
+									position++
+									break
+								case ';':
+									if buffer[position] != rune(';') {
+										goto l130
+									}
+									position++
+									break
+								case '"':
+									if buffer[position] != rune('"') {
+										goto l130
+									}
+									position++
+									break
+								case '\\':
+									if buffer[position] != rune('\\') {
+										goto l130
+									}
+									position++
+									break
+								case '\t':
+									if buffer[position] != rune('\t') {
+										goto l130
+									}
+									position++
+									break
+								default:
+									if buffer[position] != rune(' ') {
+										goto l130
+									}
+									position++
+									break
+								}
+							}
+
+							goto l90
+						l130:
+							position, tokenIndex, depth = position130, tokenIndex130, depth130
+						}
+						if !matchDot() {
+							goto l90
+						}
+					}
+				l123:
 					goto l89
 				l90:
 					position, tokenIndex, depth = position90, tokenIndex90, depth90
@@ -1224,7 +1376,9 @@ func (p *config) Init() {
 			position, tokenIndex, depth = position87, tokenIndex87, depth87
 			return false
 		},
-		/* 6 SpaceComment <- <((&('\n' | '\r') EndOfLine) | (&('#') Comment) | (&('\t' | ' ') Space+))> */
+		/* 7 Quoted <- <('"' (('\\' .) / (!((&('\n') '\n') | (&('\r') ('\r' '\n')) | (&('\\') '\\') | (&('"') '"')) .))* '"')> */
+		nil,
+		/* 8 SpaceComment <- <((&('\n' | '\r') EndOfLine) | (&('#' | ';') Comment) | (&('\t' | ' ') Space+))> */
 		func() bool {
 			position95, tokenIndex95, depth95 := position, tokenIndex, depth
 			{
@@ -1237,38 +1391,9 @@ func (p *config) Init() {
 							goto l95
 						}
 						break
-					case '#':
-						{
-							position98 := position
-							depth++
-							if buffer[position] != rune('#') {
-								goto l95
-							}
-							position++
-						l99:
-							{
-								position100, tokenIndex100, depth100 := position, tokenIndex, depth
-								{
-									position101, tokenIndex101, depth101 := position, tokenIndex, depth
-									if !_rules[ruleEndOfLine]() {
-										goto l101
-									}
-									goto l100
-								l101:
-									position, tokenIndex, depth = position101, tokenIndex101, depth101
-								}
-								if !matchDot() {
-									goto l100
-								}
-								goto l99
-							l100:
-								position, tokenIndex, depth = position100, tokenIndex100, depth100
-							}
-							if !_rules[ruleEndOfLine]() {
-								goto l95
-							}
-							depth--
-							add(ruleComment, position98)
+					case '#', ';':
+						if !_rules[ruleComment]() {
+							goto l95
 						}
 						break
 					default:
@@ -1297,9 +1422,60 @@ func (p *config) Init() {
 			position, tokenIndex, depth = position95, tokenIndex95, depth95
 			return false
 		},
-		/* 7 Comment <- <('#' (!EndOfLine .)* EndOfLine)> */
-		nil,
-		/* 8 Space <- <(' ' / '\t')> */
+		/* 9 Comment <- <([#;] (!EndOfLine .)* EndOfLine)> */
+		func() bool {
+			position97, tokenIndex97, depth97 := position, tokenIndex, depth
+			{
+				position98 := position
+				depth++
+				{
+					switch buffer[position] {
+					case ';':
+						if buffer[position] != rune(';') {
+							goto l97
+						}
+						position++
+						break
+					default:
+						if buffer[position] != rune('#') {
+							goto l97
+						}
+						position++
+						break
+					}
+				}
+
+			l99:
+				{
+					position100, tokenIndex100, depth100 := position, tokenIndex, depth
+					{
+						position101, tokenIndex101, depth101 := position, tokenIndex, depth
+						if !_rules[ruleEndOfLine]() {
+							goto l101
+						}
+						goto l100
+					l101:
+						position, tokenIndex, depth = position101, tokenIndex101, depth101
+					}
+					if !matchDot() {
+						goto l100
+					}
+					goto l99
+				l100:
+					position, tokenIndex, depth = position100, tokenIndex100, depth100
+				}
+				if !_rules[ruleEndOfLine]() {
+					goto l97
+				}
+				depth--
+				add(ruleComment, position98)
+			}
+			return true
+		l97:
+			position, tokenIndex, depth = position97, tokenIndex97, depth97
+			return false
+		},
+		/* 10 Space <- <(' ' / '\t')> */
 		func() bool {
 			position105, tokenIndex105, depth105 := position, tokenIndex, depth
 			{
@@ -1328,7 +1504,7 @@ func (p *config) Init() {
 			position, tokenIndex, depth = position105, tokenIndex105, depth105
 			return false
 		},
-		/* 9 EndOfLine <- <(('\r' '\n') / '\n' / '\r')> */
+		/* 11 EndOfLine <- <(('\r' '\n') / '\n' / '\r')> */
 		func() bool {
 			position109, tokenIndex109, depth109 := position, tokenIndex, depth
 			{
@@ -1368,14 +1544,55 @@ func (p *config) Init() {
 			position, tokenIndex, depth = position109, tokenIndex109, depth109
 			return false
 		},
+		/* 12 LineEnd <- <(Space* ((&('#' | ';') Comment) | EndOfLine))> */
+		func() bool {
+			position131, tokenIndex131, depth131 := position, tokenIndex, depth
+			{
+				position132 := position
+				depth++
+			l133:
+				{
+					position134, tokenIndex134, depth134 := position, tokenIndex, depth
+					if !_rules[ruleSpace]() {
+						goto l134
+					}
+					goto l133
+				l134:
+					position, tokenIndex, depth = position134, tokenIndex134, depth134
+				}
+				{
+					switch buffer[position] {
+					case '#', ';':
+						if !_rules[ruleComment]() {
+							goto l131
+						}
+						break
+					default:
+						if !_rules[ruleEndOfLine]() {
+							goto l131
+						}
+						break
+					}
+				}
+
+				depth--
+				add(ruleLineEnd, position132)
+			}
+			return true
+		l131:
+			position, tokenIndex, depth = position131, tokenIndex131, depth131
+			return false
+		},
+		nil,
+		/* 15 Action0 <- <{ p.addSection(text) }> */
 		nil,
-		/* 12 Action0 <- <{ p.addSection(text) }> */
+		/* 16 Action1 <- <{ p.setID(text) }> */
 		nil,
-		/* 13 Action1 <- <{ p.setID(text) }> */
+		/* 17 Action2 <- <{ p.setKey(text) }> */
 		nil,
-		/* 14 Action2 <- <{ p.setKey(text) }> */
+		/* 18 Action3 <- <{ p.addValue(text) }> */
 		nil,
-		/* 15 Action3 <- <{ p.addValue(text) }> */
+		/* 19 Action4 <- <{ p.addValue("true") }> */
 		nil,
 	}
 	p.rules = _rules
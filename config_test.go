@@ -1,6 +1,7 @@
 package gitconfig
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -177,3 +178,137 @@ var (
 	cookiefile = /Users/benburkert/.gitcookies
 `)
 )
+
+func TestParseNormalization(t *testing.T) {
+	want := []*Section{
+		{
+			Type: "remote",
+			ID:   "Origin",
+			Values: map[string]string{
+				"url":   "git@github.com:benburkert/go-gitconfig.git",
+				"fetch": "+refs/heads/*:refs/remotes/origin/*",
+			},
+		},
+		{
+			Type: "remote",
+			ID:   "Origin",
+			Values: map[string]string{
+				"fetch": "+refs/tags/*:refs/tags/*",
+			},
+		},
+		{
+			Type: "includeif",
+			ID:   `gitdir:~/work/"quoted"\`,
+			Values: map[string]string{
+				"path": "~/.gitconfig-work",
+			},
+		},
+		{
+			Type: "branch",
+			ID:   "main",
+			Values: map[string]string{
+				"merge": "",
+			},
+		},
+	}
+
+	got, err := Parse([]byte(`[Remote "Origin"]
+	URL = git@github.com:benburkert/go-gitconfig.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+	fetch = +refs/tags/*:refs/tags/*
+[includeIf "gitdir:~/work/\"quoted\"\\"]
+	path = ~/.gitconfig-work
+[branch.MAIN]
+	merge =
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want sections %#v, got %#v", want, got)
+	}
+}
+
+func TestParseBareKey(t *testing.T) {
+	want := []*Section{
+		{
+			Type: "core",
+			Values: map[string]string{
+				"bare":     "true",
+				"filemode": "false",
+			},
+		},
+		{
+			Type: "core",
+			Values: map[string]string{
+				"bare": "",
+			},
+		},
+	}
+
+	got, err := Parse([]byte("[core]\n\tbare\n\tfilemode = false\n\tbare = # empty\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want sections %#v, got %#v", want, got)
+	}
+
+	got, err = Parse([]byte("[core]\n\tbare"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []*Section{{Type: "core", Values: map[string]string{"bare": "true"}}}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want sections %#v, got %#v", want, got)
+	}
+}
+
+func TestParseHeaderKey(t *testing.T) {
+	want := []*Section{
+		{Type: "a", Values: map[string]string{"k": "v", "other": "w"}},
+		{Type: "b", ID: "c", Values: map[string]string{"flag": "true"}},
+	}
+
+	got, err := Parse([]byte("[a] k = v\n\tother = w\n[b \"c\"]flag\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want sections %#v, got %#v", want, got)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, data := range []string{
+		"[core]\n\teditor = vim\nbogus line\n",
+		"[core\n",
+		"[core]\n\teditor = \"vim\n",
+		"[core]\n\teditor = \\q\n",
+		"[core]\n\tbare true\n",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("want error parsing %q, got nil", data)
+		}
+	}
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	tests := []struct {
+		data string
+		line int
+	}{
+		{data: "[core\n", line: 1},
+		{data: "[core]\n\teditor = \"vim\n", line: 2},
+		{data: "# comment\n[core]\n\teditor = vim\n\n  bogus line\n[user]\n", line: 5},
+		{data: "[core]\r\n\tbare true", line: 2},
+		{data: "[core]\n\teditor = vim\\", line: 2},
+	}
+
+	for _, test := range tests {
+		_, err := Parse([]byte(test.data))
+		if want := fmt.Sprintf("bad config line %d", test.line); err == nil || err.Error() != want {
+			t.Errorf("%q: want error %q, got %v", test.data, want, err)
+		}
+	}
+}
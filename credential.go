@@ -0,0 +1,438 @@
+package gitconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Credential is what git and its credential helpers exchange: a
+// description of the server that wants a credential, and the credential
+// that helpers fill in.
+type Credential struct {
+	Protocol string
+	Host     string // with the port, as in "example.com:8443"
+	Path     string // without leading or trailing slashes
+	Username string
+	Password string
+
+	// PasswordExpiry is when the password expires, or zero if it does
+	// not. Git discards expired passwords that helpers return.
+	PasswordExpiry time.Time
+
+	OAuthRefreshToken string
+
+	// WWWAuth are the WWW-Authenticate headers of the server.
+	WWWAuth []string
+
+	// Quit is set by a helper to stop git from asking the helpers that
+	// follow it, or the user.
+	Quit bool
+}
+
+// CredentialFromURL returns the credential described by a URL such as
+// "https://user@example.com/repo.git". Only the scheme and host are
+// required.
+func CredentialFromURL(url string) (*Credential, error) {
+	scheme, _, ok := strings.Cut(url, "://")
+	if !ok || scheme == "" {
+		return nil, fmt.Errorf("url has no scheme: %s", url)
+	}
+
+	c := credentialFromPartialURL(url)
+	for _, field := range []struct{ name, value string }{
+		{"username", c.Username},
+		{"password", c.Password},
+		{"protocol", c.Protocol},
+		{"host", c.Host},
+		{"path", c.Path},
+	} {
+		if strings.ContainsRune(field.value, '\n') {
+			return nil, fmt.Errorf("url contains a newline in its %s component: %s", field.name, url)
+		}
+	}
+	return c, nil
+}
+
+// credentialFromPartialURL parses a URL that may be missing its scheme,
+// host or path, such as the "example.com" of a [credential "example.com"]
+// section, like git's url_to_credential.
+func credentialFromPartialURL(url string) *Credential {
+	c := &Credential{}
+
+	rest := url
+	if scheme, after, ok := strings.Cut(url, "://"); ok {
+		c.Protocol, rest = scheme, after
+	}
+
+	slash := strings.IndexAny(rest, "/?#")
+	if slash < 0 {
+		slash = len(rest)
+	}
+	authority, path := rest[:slash], rest[slash:]
+
+	host := authority
+	if at := strings.IndexByte(authority, '@'); at >= 0 {
+		userinfo := authority[:at]
+		host = authority[at+1:]
+		if user, password, ok := strings.Cut(userinfo, ":"); ok {
+			c.Username, c.Password = urlDecode(user), urlDecode(password)
+		} else {
+			c.Username = urlDecode(userinfo)
+		}
+	}
+	c.Host = urlDecode(host)
+
+	path = strings.TrimLeft(path, "/")
+	if path != "" {
+		path = urlDecode(path)
+		for len(path) > 1 && path[len(path)-1] == '/' {
+			path = path[:len(path)-1]
+		}
+	}
+	c.Path = path
+	return c
+}
+
+// MarshalText encodes c in the format of the credential helper protocol:
+// one "key=value" line per attribute that is set.
+func (c *Credential) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	write := func(key, value string) error {
+		if value == "" {
+			return nil
+		}
+		if strings.ContainsRune(value, '\n') {
+			return fmt.Errorf("credential value for %s contains newline", key)
+		}
+		buf.WriteString(key + "=" + value + "\n")
+		return nil
+	}
+
+	fields := []struct{ key, value string }{
+		{"protocol", c.Protocol},
+		{"host", c.Host},
+		{"path", c.Path},
+		{"username", c.Username},
+		{"password", c.Password},
+		{"oauth_refresh_token", c.OAuthRefreshToken},
+	}
+	if !c.PasswordExpiry.IsZero() {
+		fields = append(fields, struct{ key, value string }{"password_expiry_utc", strconv.FormatInt(c.PasswordExpiry.Unix(), 10)})
+	}
+	for _, header := range c.WWWAuth {
+		fields = append(fields, struct{ key, value string }{"wwwauth[]", header})
+	}
+
+	for _, field := range fields {
+		if err := write(field.key, field.value); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalText decodes the "key=value" lines of the credential helper
+// protocol into c, up to the first empty line. Attributes that are
+// present replace those of c, a "url" attribute replaces every attribute
+// it describes, and unknown attributes are ignored.
+func (c *Credential) UnmarshalText(data []byte) error {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			break
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("invalid credential line: %s", line)
+		}
+
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		case "oauth_refresh_token":
+			c.OAuthRefreshToken = value
+		case "password_expiry_utc":
+			c.PasswordExpiry = time.Time{}
+			if t, err := strconv.ParseInt(value, 10, 64); err == nil && t > 0 {
+				c.PasswordExpiry = time.Unix(t, 0)
+			}
+		case "wwwauth[]":
+			c.WWWAuth = append(c.WWWAuth, value)
+		case "url":
+			u, err := CredentialFromURL(value)
+			if err != nil {
+				return err
+			}
+			*c = *u
+		case "quit":
+			quit, err := parseBool(key, value)
+			if err != nil {
+				return err
+			}
+			c.Quit = quit
+		}
+	}
+	return nil
+}
+
+// expired reports whether the password of c has expired.
+func (c *Credential) expired() bool {
+	return !c.PasswordExpiry.IsZero() && c.PasswordExpiry.Before(time.Now())
+}
+
+// CredentialHelpers applies the credential configuration that matches
+// cred, as git does before running its helpers, and returns the helpers
+// to run in order. The credential.helper, credential.username and
+// credential.useHttpPath keys are read from the [credential] section and
+// from every [credential "<url>"] section whose URL matches cred, in
+// config order, and an empty helper clears the helpers before it.
+// credential.username only sets an empty cred.Username, and the path of
+// an http or https cred is dropped unless credential.useHttpPath is set.
+func (c *Config) CredentialHelpers(cred *Credential) ([]string, error) {
+	if cred.Host == "" {
+		return nil, errors.New("refusing to work with credential missing host field")
+	}
+	if cred.Protocol == "" {
+		return nil, errors.New("refusing to work with credential missing protocol field")
+	}
+
+	var (
+		helpers     []string
+		username    string
+		useHTTPPath bool
+	)
+	for _, s := range c.Sections() {
+		if s.Type != "credential" || (s.ID != "" && !matchCredentialURL(s.ID, cred)) {
+			continue
+		}
+
+		if helper, ok := s.Values["helper"]; ok {
+			if helper == "" {
+				helpers = nil
+			} else {
+				helpers = append(helpers, helper)
+			}
+		}
+		if value, ok := s.Values["username"]; ok {
+			username = value
+		}
+		if value, ok := s.Values["usehttppath"]; ok {
+			b, err := parseBool(credentialKey(s.ID, "usehttppath"), value)
+			if err != nil {
+				return nil, err
+			}
+			useHTTPPath = b
+		}
+	}
+
+	if cred.Username == "" {
+		cred.Username = username
+	}
+	if !useHTTPPath && (cred.Protocol == "http" || cred.Protocol == "https") {
+		cred.Path = ""
+	}
+	return helpers, nil
+}
+
+func credentialKey(url, name string) string {
+	if url == "" {
+		return "credential." + name
+	}
+	return "credential." + url + "." + name
+}
+
+// matchCredentialURL reports whether the URL of a [credential "<url>"]
+// section matches cred. A full URL matches like git's urlmatch: the
+// scheme and port must be equal, as must the user if the URL has one,
+// each label of the host may be a glob, and the path must be a prefix of
+// cred's on a "/" boundary. Anything else is a partial URL, whose parts
+// that are present must be equal to cred's.
+func matchCredentialURL(pattern string, cred *Credential) bool {
+	if !isURL(pattern) {
+		want := credentialFromPartialURL(pattern)
+		return (want.Protocol == "" || want.Protocol == cred.Protocol) &&
+			(want.Host == "" || want.Host == cred.Host) &&
+			(want.Path == "" || want.Path == cred.Path) &&
+			(want.Username == "" || want.Username == cred.Username)
+	}
+
+	u, err := ParseURL(pattern)
+	if err != nil || u.Transport != strings.ToLower(cred.Protocol) {
+		return false
+	}
+	if u.User != "" && u.User != cred.Username {
+		return false
+	}
+
+	host, port := cred.Host, ""
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host, port = host[:i], host[i+1:]
+	}
+	host = strings.Trim(host, "[]")
+	if !matchHost(strings.ToLower(u.Host), strings.ToLower(host)) {
+		return false
+	}
+	if defaultPort(u.Transport, u.Port) != defaultPort(u.Transport, port) {
+		return false
+	}
+	return matchURLPath("/"+cred.Path, u.Path)
+}
+
+// matchHost matches host against pattern one dot-separated label at a
+// time, so "*.example.com" matches "git.example.com" but neither
+// "example.com" nor "a.b.example.com".
+func matchHost(pattern, host string) bool {
+	patterns, labels := strings.Split(pattern, "."), strings.Split(host, ".")
+	if len(patterns) != len(labels) {
+		return false
+	}
+	for i := range patterns {
+		if !wildmatch(patterns[i], labels[i], 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultPort returns port, or the empty string if it is the default port
+// of scheme, which git's URL normalization removes.
+func defaultPort(scheme, port string) string {
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		return ""
+	}
+	return port
+}
+
+// matchURLPath reports whether prefix is path, or a prefix of it that
+// ends on a path component boundary.
+func matchURLPath(path, prefix string) bool {
+	if prefix == "" || prefix == "/" {
+		return true
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// FillCredential asks the credential helpers of conf for the username and
+// password of cred, and stops at the first helper that provides both. A
+// cred that already has both is left alone. Helpers that fail are skipped
+// like git skips them, expired passwords are discarded, and a helper that
+// sets quit without completing cred stops the search with an error.
+// Unlike git, it does not prompt when the helpers come up empty, so cred
+// may still be missing its username or password.
+func (c *Config) FillCredential(cred *Credential) error {
+	if cred.Username != "" && cred.Password != "" {
+		return nil
+	}
+
+	helpers, err := c.CredentialHelpers(cred)
+	if err != nil {
+		return err
+	}
+
+	for _, helper := range helpers {
+		RunCredentialHelper(helper, "get", cred)
+
+		if cred.expired() {
+			cred.Password, cred.PasswordExpiry = "", time.Time{}
+		}
+		if cred.Username != "" && cred.Password != "" {
+			return nil
+		}
+		if cred.Quit {
+			return fmt.Errorf("credential helper '%s' told us to quit", helper)
+		}
+	}
+	return nil
+}
+
+// ApproveCredential tells the credential helpers of conf to store cred
+// after it was used successfully. Credentials without a username or
+// password, or with an expired password, are not stored.
+func (c *Config) ApproveCredential(cred *Credential) error {
+	if cred.Username == "" || cred.Password == "" || cred.expired() {
+		return nil
+	}
+
+	helpers, err := c.CredentialHelpers(cred)
+	if err != nil {
+		return err
+	}
+	for _, helper := range helpers {
+		RunCredentialHelper(helper, "store", cred)
+	}
+	return nil
+}
+
+// RejectCredential tells the credential helpers of conf to erase cred
+// after it was refused, and clears its secrets.
+func (c *Config) RejectCredential(cred *Credential) error {
+	helpers, err := c.CredentialHelpers(cred)
+	if err != nil {
+		return err
+	}
+	for _, helper := range helpers {
+		RunCredentialHelper(helper, "erase", cred)
+	}
+
+	cred.Username, cred.Password, cred.OAuthRefreshToken = "", "", ""
+	cred.PasswordExpiry = time.Time{}
+	return nil
+}
+
+// RunCredentialHelper runs a credential.helper value for operation, which
+// is "get", "store" or "erase", with cred on its standard input. The
+// helper is run with the shell: a helper starting with "!" is a shell
+// command, an absolute path is a program, and any other helper <name> is
+// "git credential-<name>", followed by its arguments and the operation.
+// The attributes a "get" helper writes are read back into cred, even if
+// it then exits with an error.
+func RunCredentialHelper(helper, operation string, cred *Credential) error {
+	var command string
+	switch {
+	case strings.HasPrefix(helper, "!"):
+		command = helper[1:]
+	case filepath.IsAbs(helper):
+		command = helper
+	default:
+		command = "git credential-" + helper
+	}
+	command += " " + operation
+
+	input, err := cred.MarshalText()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	if operation != "get" {
+		return cmd.Run()
+	}
+
+	output, err := cmd.Output()
+	if err := cred.UnmarshalText(output); err != nil {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("credential helper '%s' failed: %w", helper, err)
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// A Repository is the location of a git repository found by Discover.
+type Repository struct {
+	// GitDir is the git directory. In a linked worktree it is the
+	// worktree's private directory below $GIT_COMMON_DIR/worktrees.
+	GitDir string
+
+	// CommonDir is the directory shared by every worktree, which holds
+	// the objects, refs and local config. It equals GitDir outside of
+	// linked worktrees.
+	CommonDir string
+
+	// WorkTree is the top level of the working tree. It is empty if Bare,
+	// and for a git directory found by itself whose core.bare is false.
+	WorkTree string
+
+	// Bare is set for repositories without a working tree. A discovered
+	// repository is bare by its layout unless core.bare says otherwise.
+	Bare bool
+}
+
+// Discover finds the repository that contains dir the way git does, using
+// the environment of the os.
+func Discover(dir string) (*Repository, error) {
+	return (&Loader{}).Discover(dir)
+}
+
+// Discover finds the repository that contains dir. If GIT_DIR is set it
+// names the git directory, and GIT_WORK_TREE the working tree. Otherwise
+// dir and each of its parents is checked for a .git directory or gitfile,
+// or for being a bare repository itself. The search stops below the
+// directories listed in GIT_CEILING_DIRECTORIES, and at filesystem
+// boundaries unless GIT_DISCOVERY_ACROSS_FILESYSTEM is set.
+//
+// Like git, a discovered repository owned by another user is only used if
+// safe.directory allows it, and a discovered bare repository only if
+// safe.bareRepository allows it. A DubiousOwnershipError is returned
+// otherwise.
+//
+// The Loader's GitDir and CommonDir can be set from the returned
+// Repository to load its local and worktree scopes.
+func (l *Loader) Discover(dir string) (*Repository, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if gitDir, ok := l.lookupEnv("GIT_DIR"); ok && gitDir != "" {
+		return l.explicitRepository(gitDir, dir)
+	}
+
+	if real, err := l.fileSystem().EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+
+	ceiling := l.ceiling(dir)
+	acrossFS, err := l.envBool("GIT_DISCOVERY_ACROSS_FILESYSTEM")
+	if err != nil {
+		return nil, err
+	}
+	dev, devOK := l.device(dir)
+
+	for {
+		repo, err := l.repositoryAt(dir)
+		if err != nil || repo != nil {
+			return repo, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || len(parent) <= ceiling {
+			return nil, errors.New("not a git repository (or any of the parent directories): .git")
+		}
+		if !acrossFS && devOK {
+			if pdev, ok := l.device(parent); ok && pdev != dev {
+				return nil, fmt.Errorf("not a git repository (or any parent up to mount point %s)\nStopping at filesystem boundary (GIT_DISCOVERY_ACROSS_FILESYSTEM not set).", dir)
+			}
+		}
+		dir = parent
+	}
+}
+
+// repositoryAt returns the repository whose working tree or bare git
+// directory is dir, or nil if there is none.
+func (l *Loader) repositoryAt(dir string) (*Repository, error) {
+	dotGit := filepath.Join(dir, ".git")
+	if fi, err := l.fileSystem().Stat(dotGit); err == nil {
+		gitDir, gitFile := dotGit, ""
+		if !fi.IsDir() {
+			gitFile = dotGit
+			if gitDir, err = l.readGitFile(dotGit); err != nil {
+				return nil, err
+			}
+		}
+		if l.isGitDir(gitDir) {
+			return l.checkedRepository(gitDir, dir, gitFile)
+		}
+	}
+
+	if l.isGitDir(dir) {
+		return l.checkedRepository(dir, "", "")
+	}
+	return nil, nil
+}
+
+// checkedRepository returns the discovered repository at gitDir after
+// checking that it is safe to use.
+func (l *Loader) checkedRepository(gitDir, workTree, gitFile string) (*Repository, error) {
+	repo, err := l.repository(gitDir, workTree, workTree == "")
+	if err != nil {
+		return nil, err
+	}
+	if err := l.checkRepository(repo, workTree, gitFile); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// explicitRepository returns the repository named by GIT_DIR, which may be
+// a gitfile. Its working tree is GIT_WORK_TREE, core.worktree, or else
+// dir unless the repository is bare.
+func (l *Loader) explicitRepository(gitDir, dir string) (*Repository, error) {
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	if fi, err := l.fileSystem().Stat(gitDir); err == nil && !fi.IsDir() {
+		var err error
+		if gitDir, err = l.readGitFile(gitDir); err != nil {
+			return nil, err
+		}
+	}
+	if !l.isGitDir(gitDir) {
+		return nil, fmt.Errorf("not a git repository: '%s'", gitDir)
+	}
+	return l.repository(gitDir, dir, false)
+}
+
+// repository completes the Repository at gitDir. workTree is where it was
+// found, or its default if GIT_DIR is set, and bare is the default of
+// core.bare.
+func (l *Loader) repository(gitDir, workTree string, bare bool) (*Repository, error) {
+	repo := &Repository{
+		GitDir:    filepath.Clean(gitDir),
+		CommonDir: l.readCommonDir(gitDir),
+	}
+
+	sections, err := l.readFile(filepath.Join(repo.CommonDir, "config"))
+	if err != nil {
+		return nil, err
+	}
+	conf := &Config{Layers: []*Layer{{Scope: ScopeLocal, Sections: sections}}}
+
+	if bare, err = conf.Bool("core.bare", bare); err != nil {
+		return nil, err
+	}
+
+	switch tree, ok := l.lookupEnv("GIT_WORK_TREE"); {
+	case ok && tree != "":
+		repo.WorkTree = tree
+	default:
+		if tree, ok := conf.Get("core.worktree"); ok {
+			repo.WorkTree = tree
+			if !filepath.IsAbs(tree) {
+				repo.WorkTree = filepath.Join(repo.GitDir, tree)
+			}
+		} else if !bare {
+			repo.WorkTree = workTree
+		}
+	}
+
+	if repo.WorkTree == "" {
+		repo.Bare = bare
+	} else if abs, err := filepath.Abs(repo.WorkTree); err == nil {
+		repo.WorkTree = abs
+	}
+	return repo, nil
+}
+
+// readGitFile returns the git directory named by the "gitdir: <path>" line
+// of a .git file, relative to the directory of the file.
+func (l *Loader) readGitFile(path string) (string, error) {
+	data, err := l.fileSystem().ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	gitDir, ok := strings.CutPrefix(strings.TrimRight(string(data), "\r\n"), "gitdir: ")
+	if !ok || gitDir == "" {
+		return "", fmt.Errorf("invalid gitfile format: %s", path)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	if !l.isGitDir(gitDir) {
+		return "", fmt.Errorf("not a git repository: %s", gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// readCommonDir returns the directory named by the commondir file of
+// gitDir, or gitDir itself.
+func (l *Loader) readCommonDir(gitDir string) string {
+	data, err := l.fileSystem().ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return filepath.Clean(gitDir)
+	}
+
+	dir := strings.TrimRight(string(data), "\r\n")
+	if dir == "" {
+		return filepath.Clean(gitDir)
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// isGitDir reports whether dir looks like a git directory: it has a valid
+// HEAD, and its common directory has objects and refs directories.
+func (l *Loader) isGitDir(dir string) bool {
+	if !l.validHead(filepath.Join(dir, "HEAD")) {
+		return false
+	}
+
+	common := l.readCommonDir(dir)
+	objects := filepath.Join(common, "objects")
+	if env, ok := l.lookupEnv("GIT_OBJECT_DIRECTORY"); ok {
+		objects = env
+	}
+	for _, path := range []string{objects, filepath.Join(common, "refs")} {
+		if fi, err := l.fileSystem().Stat(path); err != nil || !fi.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// validHead reports whether the file at path is a symbolic ref to
+// "refs/..." or holds an object name.
+func (l *Loader) validHead(path string) bool {
+	data, err := l.fileSystem().ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	head := strings.TrimRight(string(data), "\r\n")
+	if ref, ok := strings.CutPrefix(head, "ref:"); ok {
+		return strings.HasPrefix(strings.TrimLeft(ref, " \t"), "refs/")
+	}
+	return isObjectName(head)
+}
+
+// isObjectName reports whether s is a hex SHA-1 or SHA-256 object name.
+func isObjectName(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isDigit(c) && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// ceiling returns the length of the longest GIT_CEILING_DIRECTORIES entry
+// that is a parent of dir, or -1. Discovery does not look at the ceiling
+// directories themselves or above them.
+func (l *Loader) ceiling(dir string) int {
+	ceiling := -1
+	for _, entry := range filepath.SplitList(l.getenv("GIT_CEILING_DIRECTORIES")) {
+		if !filepath.IsAbs(entry) {
+			continue
+		}
+		if real, err := l.fileSystem().EvalSymlinks(entry); err == nil {
+			entry = real
+		}
+		entry = filepath.Clean(entry)
+
+		prefix := entry
+		if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+			prefix += string(filepath.Separator)
+		}
+		if strings.HasPrefix(dir, prefix) && len(entry) > ceiling {
+			ceiling = len(entry)
+		}
+	}
+	return ceiling
+}
+
+func (l *Loader) device(dir string) (uint64, bool) {
+	fi, err := l.fileSystem().Stat(dir)
+	if err != nil {
+		return 0, false
+	}
+	return fileDevice(fi)
+}
@@ -0,0 +1,178 @@
+package gitconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeGitDir creates the HEAD, objects and refs of a git directory.
+func writeGitDir(t *testing.T, dir string) {
+	t.Helper()
+
+	for _, sub := range []string{"objects", "refs"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeGitDir(t, filepath.Join(dir, "repo", ".git"))
+	writeGitDir(t, filepath.Join(dir, "bare.git"))
+	writeGitDir(t, filepath.Join(dir, "barecfg", ".git"))
+	writeGitDir(t, filepath.Join(dir, "nonbare.git"))
+	writeFiles(t, dir, map[string]string{
+		"repo/a/b/file":                          "",
+		"repo/.git/config":                       "[extensions]\n\tworktreeConfig = true\n[core]\n\teditor = vim\n",
+		"repo/.git/worktrees/wt/HEAD":            "ref: refs/heads/wt\n",
+		"repo/.git/worktrees/wt/commondir":       "../..\n",
+		"repo/.git/worktrees/wt/config.worktree": "[core]\n\teditor = emacs\n",
+		"wt/.git":                                "gitdir: ../repo/.git/worktrees/wt\n",
+		"wt/sub/file":                            "",
+		"explicit/file":                          "",
+		"barecfg/.git/config":                    "[core]\n\tbare = true\n",
+		"barecfg/sub/file":                       "",
+		"nonbare.git/config":                     "[core]\n\tbare = false\n",
+	})
+
+	tests := []struct {
+		dir  string
+		env  map[string]string
+		want *Repository
+	}{
+		{
+			dir: "repo/a/b",
+			want: &Repository{
+				GitDir:    filepath.Join(dir, "repo", ".git"),
+				CommonDir: filepath.Join(dir, "repo", ".git"),
+				WorkTree:  filepath.Join(dir, "repo"),
+			},
+		},
+		{
+			dir: "bare.git/refs",
+			want: &Repository{
+				GitDir:    filepath.Join(dir, "bare.git"),
+				CommonDir: filepath.Join(dir, "bare.git"),
+				Bare:      true,
+			},
+		},
+		{
+			dir: "barecfg/sub",
+			want: &Repository{
+				GitDir:    filepath.Join(dir, "barecfg", ".git"),
+				CommonDir: filepath.Join(dir, "barecfg", ".git"),
+				Bare:      true,
+			},
+		},
+		{
+			dir: "nonbare.git",
+			want: &Repository{
+				GitDir:    filepath.Join(dir, "nonbare.git"),
+				CommonDir: filepath.Join(dir, "nonbare.git"),
+			},
+		},
+		{
+			dir: "wt/sub",
+			want: &Repository{
+				GitDir:    filepath.Join(dir, "repo", ".git", "worktrees", "wt"),
+				CommonDir: filepath.Join(dir, "repo", ".git"),
+				WorkTree:  filepath.Join(dir, "wt"),
+			},
+		},
+		{
+			dir: "explicit",
+			env: map[string]string{
+				"GIT_DIR":       filepath.Join(dir, "repo", ".git"),
+				"GIT_WORK_TREE": filepath.Join(dir, "repo"),
+			},
+			want: &Repository{
+				GitDir:    filepath.Join(dir, "repo", ".git"),
+				CommonDir: filepath.Join(dir, "repo", ".git"),
+				WorkTree:  filepath.Join(dir, "repo"),
+			},
+		},
+		{
+			dir: "explicit",
+			env: map[string]string{"GIT_DIR": "../bare.git"},
+			want: &Repository{
+				GitDir:    filepath.Join(dir, "bare.git"),
+				CommonDir: filepath.Join(dir, "bare.git"),
+				WorkTree:  filepath.Join(dir, "explicit"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		l := &Loader{Env: testEnv(test.env)}
+		repo, err := l.Discover(filepath.Join(dir, test.dir))
+		if err != nil {
+			t.Errorf("%s: %v", test.dir, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.want, repo) {
+			t.Errorf("%s: want %+v, got %+v", test.dir, test.want, repo)
+		}
+	}
+
+	// a linked worktree reads the shared local config and its own
+	// worktree config.
+	repo, err := (&Loader{Env: testEnv(nil)}).Discover(filepath.Join(dir, "wt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &Loader{GitDir: repo.GitDir, CommonDir: repo.CommonDir, Env: testEnv(map[string]string{"GIT_CONFIG_NOSYSTEM": "1"})}
+	conf, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"vim", "emacs"}, conf.GetAll("core.editor"); !reflect.DeepEqual(want, got) {
+		t.Errorf("want core.editor %q, got %q", want, got)
+	}
+}
+
+func TestDiscoverErrors(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeGitDir(t, filepath.Join(dir, "repo", ".git"))
+	writeFiles(t, dir, map[string]string{
+		"repo/a/file":  "",
+		"bad/.git":     "not a gitfile\n",
+		"missing/.git": "gitdir: nowhere\n",
+		"outside/file": "",
+	})
+
+	tests := []struct {
+		dir  string
+		env  map[string]string
+		want string
+	}{
+		{"repo/a", map[string]string{"GIT_CEILING_DIRECTORIES": filepath.Join(dir, "repo")}, "not a git repository (or any of the parent directories): .git"},
+		{"bad", nil, "invalid gitfile format: " + filepath.Join(dir, "bad", ".git")},
+		{"missing", nil, "not a git repository: " + filepath.Join(dir, "missing", "nowhere")},
+		{"outside", map[string]string{"GIT_DIR": filepath.Join(dir, "outside")}, "not a git repository: '" + filepath.Join(dir, "outside") + "'"},
+	}
+
+	for _, test := range tests {
+		// keep the search inside of the test directory.
+		env := map[string]string{"GIT_CEILING_DIRECTORIES": dir}
+		for k, v := range test.env {
+			env[k] = v
+		}
+
+		_, err := (&Loader{Env: testEnv(env)}).Discover(filepath.Join(dir, test.dir))
+		if err == nil || err.Error() != test.want {
+			t.Errorf("%s: want error %q, got %v", test.dir, test.want, err)
+		}
+	}
+}
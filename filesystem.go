@@ -0,0 +1,125 @@
+package gitconfig
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// A FileSystem is where a Loader reads config files, repository metadata
+// and home directories from.
+type FileSystem interface {
+	// Open opens the named file for reading. Packfiles are read in place
+	// if the file implements io.ReaderAt, and read whole otherwise.
+	Open(name string) (fs.File, error)
+
+	// ReadFile returns the contents of the named file.
+	ReadFile(name string) ([]byte, error)
+
+	// Stat returns the FileInfo of the named file, following symlinks.
+	Stat(name string) (fs.FileInfo, error)
+
+	// ReadDir returns the entries of the named directory.
+	ReadDir(name string) ([]fs.DirEntry, error)
+
+	// EvalSymlinks returns path with any symbolic links resolved.
+	EvalSymlinks(path string) (string, error)
+
+	// HomeDir returns the home directory of the named user, used to
+	// expand "~user/" paths.
+	HomeDir(username string) (string, error)
+}
+
+// OSFileSystem is the FileSystem of the operating system.
+var OSFileSystem FileSystem = osFileSystem{}
+
+type osFileSystem struct{}
+
+func (osFileSystem) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFileSystem) EvalSymlinks(path string) (string, error) {
+	return filepath.EvalSymlinks(path)
+}
+
+func (osFileSystem) HomeDir(username string) (string, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return "", err
+	}
+	return u.HomeDir, nil
+}
+
+// FS returns a FileSystem that reads from fsys, such as an embed.FS or an
+// fstest.MapFS. Absolute paths are looked up relative to the root of fsys,
+// so "/home/ben/.gitconfig" is read as "home/ben/.gitconfig". The home
+// directory of a user is looked up as "home/<user>", and fsys has no
+// symlinks.
+func FS(fsys fs.FS) FileSystem {
+	return fsFileSystem{fsys}
+}
+
+type fsFileSystem struct {
+	fsys fs.FS
+}
+
+func (f fsFileSystem) Open(name string) (fs.File, error) {
+	return f.fsys.Open(fsName(name))
+}
+
+func (f fsFileSystem) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(f.fsys, fsName(name))
+}
+
+func (f fsFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(f.fsys, fsName(name))
+}
+
+func (f fsFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(f.fsys, fsName(name))
+}
+
+func (f fsFileSystem) EvalSymlinks(path string) (string, error) {
+	if _, err := f.Stat(path); err != nil {
+		return "", err
+	}
+	return filepath.Clean(path), nil
+}
+
+func (f fsFileSystem) HomeDir(username string) (string, error) {
+	home := "/home/" + username
+	fi, err := f.Stat(home)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return "", errors.New("not a directory: " + home)
+	}
+	return home, nil
+}
+
+// fsName converts a path to the unrooted, slash separated form of fs.FS.
+func fsName(name string) string {
+	name = strings.TrimLeft(path.Clean(filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
@@ -0,0 +1,59 @@
+package gitconfig
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoaderFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"etc/gitconfig": {Data: []byte("[core]\n\tautocrlf = input\n")},
+		"home/ben/.gitconfig": {Data: []byte(`[user]
+	name = Ben Burkert
+[include]
+	path = ~ben/shared.gitconfig
+[includeIf "gitdir:~/src/"]
+	path = src.gitconfig
+[includeIf "onbranch:main"]
+	path = main.gitconfig
+`)},
+		"home/ben/shared.gitconfig":     {Data: []byte("[alias]\n\tst = status\n")},
+		"home/ben/src.gitconfig":        {Data: []byte("[user]\n\temail = ben@benburkert.com\n")},
+		"home/ben/main.gitconfig":       {Data: []byte("[test]\n\tbranch = main\n")},
+		"home/ben/src/repo/.git/HEAD":   {Data: []byte("ref: refs/heads/main\n")},
+		"home/ben/src/repo/.git/config": {Data: []byte("[core]\n\tbare = false\n")},
+	}
+
+	l := &Loader{
+		GitDir: "/home/ben/src/repo/.git",
+		Env:    testEnv(map[string]string{"HOME": "/home/ben"}),
+		FS:     FS(fsys),
+	}
+	conf, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, layer := range conf.Layers {
+		paths = append(paths, layer.Path)
+	}
+	if want := []string{"/etc/gitconfig", "/home/ben/.gitconfig", "/home/ben/src/repo/.git/config"}; !reflect.DeepEqual(want, paths) {
+		t.Errorf("want layers %q, got %q", want, paths)
+	}
+
+	values := map[string]string{
+		"core.autocrlf": "input",
+		"user.name":     "Ben Burkert",
+		"user.email":    "ben@benburkert.com",
+		"alias.st":      "status",
+		"test.branch":   "main",
+		"core.bare":     "false",
+	}
+	for key, want := range values {
+		if got, _ := conf.Get(key); got != want {
+			t.Errorf("want %s = %q, got %q", key, want, got)
+		}
+	}
+}
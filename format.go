@@ -0,0 +1,248 @@
+package gitconfig
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// FormatOptions control how Format lays out a config file.
+type FormatOptions struct {
+	// Sort orders sections by name and subsection, and the keys within
+	// each section by name. The sort is stable, so repeated sections and
+	// multi-valued keys keep their relative order. Note that moving an
+	// [include] section changes which values it overrides.
+	Sort bool
+}
+
+// Format returns the canonical formatting of the config file src. Keys are
+// indented with one tab, values are separated from keys by " = ", section
+// headers are written as [name "subsection"], and runs of blank lines are
+// collapsed. Comments stay attached to the line they describe: a comment
+// directly above a line, or at the end of it, moves with that line.
+func Format(src []byte, opts FormatOptions) ([]byte, error) {
+	f, err := parseFormat(src)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Sort {
+		f.sort()
+	}
+	return f.bytes(), nil
+}
+
+// IsFormatted reports whether src is unchanged by Format.
+func IsFormatted(src []byte, opts FormatOptions) (bool, error) {
+	out, err := Format(src, opts)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(src, out), nil
+}
+
+// A fmtBlock is a run of comment lines and the config line they describe.
+type fmtBlock struct {
+	blank    bool     // preceded by a blank line
+	comments []string // full line comments above line
+	line     string   // header or "key = value", empty for comments only
+	trailing string   // comment at the end of line
+	key      string   // sort key
+}
+
+type fmtSection struct {
+	name, id string
+	header   *fmtBlock
+	blocks   []*fmtBlock
+}
+
+type fmtFile struct {
+	preamble []*fmtBlock
+	sections []*fmtSection
+}
+
+func parseFormat(src []byte) (*fmtFile, error) {
+	conf, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		buffer = conf.buffer
+		lines  = lineStarts(buffer)
+
+		f       = &fmtFile{}
+		sec     *fmtSection
+		pending = &fmtBlock{}
+		last    = -1 // line of the last thing added
+		lastBlk *fmtBlock
+
+		text, key string
+		keyLine   int
+	)
+
+	lineOf := func(pos uint32) int {
+		return sort.Search(len(lines), func(i int) bool { return lines[i] > int(pos) }) - 1
+	}
+
+	// gap starts a new block if there are blank lines between the last
+	// line and line. Comments separated from what follows by a blank line
+	// are kept where they are as a block of their own.
+	gap := func(line int) {
+		if last < 0 || line <= last+1 {
+			return
+		}
+		if len(pending.comments) > 0 {
+			if sec == nil {
+				f.preamble = append(f.preamble, pending)
+			} else {
+				sec.blocks = append(sec.blocks, pending)
+			}
+			pending = &fmtBlock{}
+		}
+		pending.blank = true
+	}
+
+	add := func(line int, text string) {
+		gap(line)
+		blk := pending
+		blk.line = text
+		pending, lastBlk = &fmtBlock{}, blk
+	}
+
+	for token := range conf.tokenTree.Tokens() {
+		begin, end := int(token.begin), int(token.end)
+
+		switch token.pegRule {
+		case rulePegText:
+			text = string(buffer[begin:end])
+		case ruleAction0:
+			add(lineOf(token.begin), "")
+			sec = &fmtSection{header: lastBlk, name: text}
+			if i := strings.IndexByte(text, '.'); i >= 0 {
+				// deprecated [section.subsection] syntax
+				sec.name, sec.id = text[:i], strings.ToLower(text[i+1:])
+			}
+			sec.header.line, sec.header.key = sec.format()
+			f.sections = append(f.sections, sec)
+			last = lineOf(token.begin)
+		case ruleAction1:
+			sec.id = unescapeID(text)
+			sec.header.line, sec.header.key = sec.format()
+		case ruleAction2:
+			key, keyLine = text, lineOf(token.begin)
+		case ruleAction3:
+			if text == "" {
+				add(keyLine, key+" =")
+			} else {
+				add(keyLine, key+" = "+text)
+			}
+			lastBlk.key = strings.ToLower(key)
+			sec.blocks = append(sec.blocks, lastBlk)
+			last = lineOf(token.begin)
+		case ruleAction4:
+			// a key without a value is true, unlike "key =".
+			add(keyLine, key)
+			lastBlk.key = strings.ToLower(key)
+			sec.blocks = append(sec.blocks, lastBlk)
+			last = lineOf(token.begin)
+		case ruleComment:
+			comment := strings.TrimRight(string(buffer[begin:end]), " \t\r\n")
+			line := lineOf(token.begin)
+			if line == last && lastBlk != nil && lastBlk.trailing == "" {
+				lastBlk.trailing = comment
+				continue
+			}
+			gap(line)
+			pending.comments = append(pending.comments, comment)
+			last = line
+		}
+	}
+
+	if len(pending.comments) > 0 {
+		if sec == nil {
+			f.preamble = append(f.preamble, pending)
+		} else {
+			sec.blocks = append(sec.blocks, pending)
+		}
+	}
+	return f, nil
+}
+
+func (f *fmtFile) sort() {
+	sort.SliceStable(f.sections, func(i, j int) bool {
+		return f.sections[i].header.key < f.sections[j].header.key
+	})
+
+	for _, sec := range f.sections {
+		// comment only blocks stay below the line they follow.
+		var groups [][]*fmtBlock
+		for _, blk := range sec.blocks {
+			if blk.line == "" && len(groups) > 0 {
+				groups[len(groups)-1] = append(groups[len(groups)-1], blk)
+			} else {
+				groups = append(groups, []*fmtBlock{blk})
+			}
+		}
+
+		sort.SliceStable(groups, func(i, j int) bool {
+			return groups[i][0].key < groups[j][0].key
+		})
+
+		sec.blocks = sec.blocks[:0]
+		for _, group := range groups {
+			sec.blocks = append(sec.blocks, group...)
+		}
+	}
+}
+
+func (f *fmtFile) bytes() []byte {
+	var buf bytes.Buffer
+
+	write := func(blk *fmtBlock, indent string, first bool) {
+		if blk.blank && !first {
+			buf.WriteByte('\n')
+		}
+		for _, comment := range blk.comments {
+			buf.WriteString(indent + comment + "\n")
+		}
+		if blk.line == "" {
+			return
+		}
+		buf.WriteString(indent + blk.line)
+		if blk.trailing != "" {
+			buf.WriteString(" " + blk.trailing)
+		}
+		buf.WriteByte('\n')
+	}
+
+	for _, blk := range f.preamble {
+		write(blk, "", buf.Len() == 0)
+	}
+	for _, sec := range f.sections {
+		write(sec.header, "", buf.Len() == 0)
+		for i, blk := range sec.blocks {
+			write(blk, "\t", i == 0)
+		}
+	}
+	return buf.Bytes()
+}
+
+// format returns the header line of sec and its sort key.
+func (sec *fmtSection) format() (line, key string) {
+	key = strings.ToLower(sec.name) + "\x00" + sec.id
+	if sec.id == "" {
+		return "[" + sec.name + "]", key
+	}
+	return "[" + sec.name + ` "` + escapeID(sec.id) + `"]`, key
+}
+
+// lineStarts returns the offset of the start of each line in buffer.
+func lineStarts(buffer []rune) []int {
+	starts := []int{0}
+	for i, c := range buffer {
+		if c == '\n' || (c == '\r' && (i+1 == len(buffer) || buffer[i+1] != '\n')) {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
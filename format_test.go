@@ -0,0 +1,155 @@
+package gitconfig
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		opts FormatOptions
+		src  string
+		want string
+	}{
+		{
+			name: "layout",
+			src: `[user]
+  name=Ben Burkert
+    email   =   ben@benburkert.com
+[ color    "branch" ]
+current = yellow reverse
+`,
+			want: `[user]
+	name = Ben Burkert
+	email = ben@benburkert.com
+[color "branch"]
+	current = yellow reverse
+`,
+		},
+		{
+			name: "bare key",
+			src:  "[core]\n  bare   # implicitly true\n  editor =\n",
+			want: "[core]\n\tbare # implicitly true\n\teditor =\n",
+		},
+		{
+			name: "key on header line",
+			src:  "[a] k = v # inline\n\tother = w\n[b]\tflag\n",
+			want: "[a]\n\tk = v # inline\n\tother = w\n[b]\n\tflag\n",
+		},
+		{
+			name: "comments",
+			src: `# global settings
+
+
+[core] ; header comment
+  # the editor
+  editor = vim    # trailing
+  pager = less
+  # end of core
+
+[diff]
+	tool = vimdiff`,
+			want: `# global settings
+
+[core] ; header comment
+	# the editor
+	editor = vim # trailing
+	pager = less
+	# end of core
+
+[diff]
+	tool = vimdiff
+`,
+		},
+		{
+			name: "deprecated subsection",
+			src:  "[branch.Main]\n\tremote = origin\n",
+			want: "[branch \"main\"]\n\tremote = origin\n",
+		},
+		{
+			name: "subsection quoting",
+			src:  "[url \"git@github.com:\"]\n\tinsteadOf=https://github.com/\n[branch \"a\\b\\\"c\"]\n\tmerge=\n",
+			want: "[url \"git@github.com:\"]\n\tinsteadOf = https://github.com/\n[branch \"ab\\\"c\"]\n\tmerge =\n",
+		},
+		{
+			name: "sort",
+			opts: FormatOptions{Sort: true},
+			src: `[user]
+	# who
+	name = Ben
+	email = ben@example.com # work
+[color "diff"]
+	old = red
+[color]
+	ui = auto
+[alias]
+	s = status
+	ap = add -p
+	ap = add --patch
+`,
+			want: `[alias]
+	ap = add -p
+	ap = add --patch
+	s = status
+[color]
+	ui = auto
+[color "diff"]
+	old = red
+[user]
+	email = ben@example.com # work
+	# who
+	name = Ben
+`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Format([]byte(test.src), test.opts)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if test.want != string(got) {
+			t.Errorf("%s: want\n%s\ngot\n%s", test.name, test.want, got)
+		}
+
+		again, err := Format(got, test.opts)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if string(got) != string(again) {
+			t.Errorf("%s: not idempotent, want\n%s\ngot\n%s", test.name, got, again)
+		}
+	}
+}
+
+func TestIsFormatted(t *testing.T) {
+	ok, err := IsFormatted(configData, FormatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("want configData to need formatting")
+	}
+
+	out, err := Format(configData, FormatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := IsFormatted(out, FormatOptions{}); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Errorf("want formatted output to be formatted:\n%s", out)
+	}
+
+	want, err := Parse(configData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != len(got) {
+		t.Errorf("want %d sections, got %d", len(want), len(got))
+	}
+}
@@ -1,16 +1,25 @@
 package gitconfig
 
-func Parse(data []byte) ([]*Section, error) {
-	conf := &config{
-		Buffer: string(data),
-	}
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
 
-	conf.Init()
-	if err := conf.Parse(); err != nil {
+// Parse parses the contents of a git config file. Section and key names
+// are lowercased, and a key that repeats within a section starts a new
+// Section with the same Type and ID, so that every value of a multi-valued
+// key is returned in file order.
+func Parse(data []byte) ([]*Section, error) {
+	conf, err := parse(data)
+	if err != nil {
 		return nil, err
 	}
 	conf.Execute()
 
+	if conf.err != nil {
+		return nil, conf.err
+	}
 	return conf.sections, nil
 }
 
@@ -18,3 +27,43 @@ type Section struct {
 	Type, ID string
 	Values   map[string]string
 }
+
+// parse runs the grammar over data, leaving the actions to the caller.
+func parse(data []byte) (*config, error) {
+	conf := &config{
+		Buffer: string(data),
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' && data[len(data)-1] != '\r' {
+		conf.Buffer += "\n"
+	}
+
+	conf.Init()
+	if err := conf.Parse(); err != nil {
+		return nil, &SyntaxError{Line: conf.errorLine(utf8.RuneCount(data))}
+	}
+	return conf, nil
+}
+
+// A SyntaxError reports the line of a config file that does not parse.
+type SyntaxError struct {
+	Line int
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("bad config line %d", e.Line)
+}
+
+// errorLine returns the line that a failed parse stopped at. Every line
+// before it was parsed, so it is the line of the furthest token. Lines are
+// counted in the size runes of the input, without the newline that parse
+// may have added to it.
+func (p *config) errorLine(size int) int {
+	end := 0
+	for token := range p.tokenTree.Tokens() {
+		end = max(end, int(token.end))
+	}
+	end = min(end, size)
+
+	lines := lineStarts(p.buffer[:size])
+	return sort.Search(len(lines), func(i int) bool { return lines[i] > end })
+}
@@ -0,0 +1,237 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// An Identity is the name and email address git records as the author or
+// committer of a commit.
+type Identity struct {
+	Name  string
+	Email string
+
+	// Date is the value of GIT_AUTHOR_DATE or GIT_COMMITTER_DATE, or
+	// empty to use the current time.
+	Date string
+
+	// Explicit is set when both the name and the email address were
+	// configured, in the config, the environment or EMAIL, rather than
+	// guessed from the system user and host name. Git warns when it
+	// commits with an identity that is not explicit.
+	Explicit bool
+}
+
+// String returns the identity in the "Name <email>" form of a commit.
+func (id *Identity) String() string {
+	return id.Name + " <" + id.Email + ">"
+}
+
+// The system user and host name that git guesses an identity from. They
+// are variables for tests.
+var (
+	currentUser   = user.Current
+	hostname      = defaultHostname
+	canonicalHost = defaultCanonicalHost
+)
+
+var defaultHostname = os.Hostname
+
+func defaultCanonicalHost(host string) (string, error) {
+	cname, err := net.LookupCNAME(host)
+	return strings.TrimSuffix(cname, "."), err
+}
+
+// Author returns the author identity of a commit made with conf: the
+// GIT_AUTHOR_NAME and GIT_AUTHOR_EMAIL variables, then author.name and
+// author.email, then user.name and user.email. An unset email address
+// falls back to EMAIL, and either falls back to the system user and host
+// name unless user.useConfigOnly is set. It returns git's errors for an
+// identity that is missing or cannot be guessed.
+func (l *Loader) Author(conf *Config) (*Identity, error) {
+	return l.identity(conf, "author")
+}
+
+// Committer returns the committer identity of a commit made with conf,
+// like Author but with GIT_COMMITTER_NAME, GIT_COMMITTER_EMAIL,
+// committer.name and committer.email.
+func (l *Loader) Committer(conf *Config) (*Identity, error) {
+	return l.identity(conf, "committer")
+}
+
+func (l *Loader) identity(conf *Config, who string) (*Identity, error) {
+	useConfigOnly, err := conf.Bool("user.useConfigOnly", false)
+	if err != nil {
+		return nil, err
+	}
+
+	env := "GIT_" + strings.ToUpper(who) + "_"
+	id := &Identity{Date: l.getenv(env + "DATE")}
+
+	// As in git, any of user.*, author.* or committer.* counts as
+	// configured for both identities, and stops the guessing of the
+	// other's default.
+	nameConfigured := isSet(conf, "user.name", "author.name", "committer.name")
+	emailConfigured := isSet(conf, "user.email", "author.email", "committer.email")
+
+	email, emailGiven := l.lookupEnv(env + "EMAIL")
+	if !emailGiven {
+		email, emailGiven = conf.Get(who + ".email")
+	}
+	if !emailGiven {
+		if useConfigOnly && !emailConfigured {
+			return nil, errors.New("no email was given and auto-detection is disabled")
+		}
+
+		var bogus bool
+		email, emailGiven, bogus = l.defaultEmail(conf, emailConfigured)
+		if bogus {
+			return nil, fmt.Errorf("unable to auto-detect email address (got '%s')", email)
+		}
+	}
+
+	name, nameGiven := l.lookupEnv(env + "NAME")
+	if !nameGiven {
+		name, nameGiven = conf.Get(who + ".name")
+	}
+	if !nameGiven {
+		if useConfigOnly && !nameConfigured {
+			return nil, errors.New("no name was given and auto-detection is disabled")
+		}
+
+		var bogus bool
+		name, nameGiven, bogus = defaultName(conf, nameConfigured)
+		if bogus {
+			return nil, fmt.Errorf("unable to auto-detect name (got '%s')", name)
+		}
+	}
+	if name == "" {
+		return nil, fmt.Errorf("empty ident name (for <%s>) not allowed", email)
+	}
+	if !hasNonCrud(name) {
+		return nil, fmt.Errorf("name consists only of disallowed characters: %s", name)
+	}
+
+	id.Name, id.Email = withoutCrud(name), withoutCrud(email)
+	id.Explicit = nameGiven && emailGiven
+	return id, nil
+}
+
+// defaultName returns user.name, or the full name of the system user if
+// no name is configured. It reports whether the name was configured, and
+// whether the system user could not be looked up.
+func defaultName(conf *Config, configured bool) (name string, given, bogus bool) {
+	if name, ok := conf.Get("user.name"); ok {
+		return name, true, false
+	}
+	if configured {
+		return "", false, false
+	}
+
+	u, err := currentUser()
+	if err != nil {
+		return "Unknown", false, true
+	}
+	return strings.TrimSpace(gecosName(u)), false, false
+}
+
+// defaultEmail returns user.email, or EMAIL, or the system user at the
+// host name if no email address is configured. It reports whether the
+// address was configured, and whether it is a guess git refuses to use.
+func (l *Loader) defaultEmail(conf *Config, configured bool) (email string, given, bogus bool) {
+	if email, ok := conf.Get("user.email"); ok {
+		return email, true, false
+	}
+	if configured {
+		return "", false, false
+	}
+	if email := l.getenv("EMAIL"); email != "" {
+		return strings.TrimSpace(email), true, false
+	}
+
+	username := "unknown"
+	if u, err := currentUser(); err == nil {
+		username = u.Username
+	} else {
+		bogus = true
+	}
+
+	domain, ok := domainName()
+	return strings.TrimSpace(username + "@" + domain), false, bogus || !ok
+}
+
+// gecosName returns the full name of u, with git's expansion of "&" to
+// the capitalized user name.
+func gecosName(u *user.User) string {
+	name, _, _ := strings.Cut(u.Name, ",")
+	if !strings.Contains(name, "&") {
+		return name
+	}
+
+	username := u.Username
+	if username != "" && 'a' <= username[0] && username[0] <= 'z' {
+		username = string(username[0]-'a'+'A') + username[1:]
+	}
+	return strings.ReplaceAll(name, "&", username)
+}
+
+// domainName returns the fully qualified host name, or the host name
+// followed by ".(none)" and false if it has no domain.
+func domainName() (string, bool) {
+	host, err := hostname()
+	if err != nil {
+		return "(none)", false
+	}
+	if strings.Contains(host, ".") {
+		return host, true
+	}
+	if cname, err := canonicalHost(host); err == nil && strings.Contains(cname, ".") {
+		return cname, true
+	}
+	return host + ".(none)", false
+}
+
+func isSet(conf *Config, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := conf.Get(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isCrud reports whether c is one of the characters git strips from the
+// ends of an identity.
+func isCrud(c byte) bool {
+	return c <= ' ' || strings.IndexByte(",:;<>\"\\'", c) >= 0
+}
+
+func hasNonCrud(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isCrud(s[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutCrud strips the crud from the ends of s, and the newlines and
+// angle brackets that delimit an identity from the rest of it.
+func withoutCrud(s string) string {
+	for s != "" && isCrud(s[0]) {
+		s = s[1:]
+	}
+	for s != "" && isCrud(s[len(s)-1]) {
+		s = s[:len(s)-1]
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '<' || r == '>' {
+			return -1
+		}
+		return r
+	}, s)
+}
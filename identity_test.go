@@ -0,0 +1,131 @@
+package gitconfig
+
+import (
+	"errors"
+	"os/user"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	currentUser = func() (*user.User, error) {
+		return &user.User{Username: "ben", Name: "& Burkert,Room 1,,"}, nil
+	}
+	hostname = func() (string, error) { return "laptop", nil }
+	canonicalHost = func(string) (string, error) { return "laptop.example.com", nil }
+	defer func() {
+		currentUser, hostname = user.Current, defaultHostname
+		canonicalHost = defaultCanonicalHost
+	}()
+
+	tests := []struct {
+		name      string
+		config    string
+		env       map[string]string
+		committer bool
+		want      Identity
+		err       string
+	}{
+		{
+			name:   "user",
+			config: "[user]\n\tname = Ben\n\temail = ben@example.com\n",
+			want:   Identity{Name: "Ben", Email: "ben@example.com", Explicit: true},
+		},
+		{
+			name:   "author override",
+			config: "[user]\n\tname = Ben\n\temail = ben@example.com\n[author]\n\temail = ben@work.example.com\n",
+			want:   Identity{Name: "Ben", Email: "ben@work.example.com", Explicit: true},
+		},
+		{
+			name:      "committer override",
+			config:    "[user]\n\tname = Ben\n\temail = ben@example.com\n[author]\n\tname = Other\n[committer]\n\tname = Bot\n",
+			committer: true,
+			want:      Identity{Name: "Bot", Email: "ben@example.com", Explicit: true},
+		},
+		{
+			name:   "environment",
+			config: "[user]\n\tname = Ben\n\temail = ben@example.com\n",
+			env:    map[string]string{"GIT_AUTHOR_NAME": "Env", "GIT_AUTHOR_EMAIL": "<env@example.com>", "GIT_AUTHOR_DATE": "@0 +0000"},
+			want:   Identity{Name: "Env", Email: "env@example.com", Date: "@0 +0000", Explicit: true},
+		},
+		{
+			name:   "EMAIL",
+			config: "[user]\n\tname = Ben\n",
+			env:    map[string]string{"EMAIL": "ben@mail.example.com"},
+			want:   Identity{Name: "Ben", Email: "ben@mail.example.com", Explicit: true},
+		},
+		{
+			name: "system",
+			want: Identity{Name: "Ben Burkert", Email: "ben@laptop.example.com"},
+		},
+		{
+			name:   "crud",
+			config: "[user]\n\tname = \" <Ben>, \"\n\temail = ben@example.com\n",
+			want:   Identity{Name: "Ben", Email: "ben@example.com", Explicit: true},
+		},
+		{
+			name:   "use config only",
+			config: "[user]\n\tname = Ben\n\tuseConfigOnly = true\n",
+			err:    "no email was given and auto-detection is disabled",
+		},
+		{
+			name:   "use config only name",
+			config: "[user]\n\temail = ben@example.com\n\tuseConfigOnly = true\n",
+			err:    "no name was given and auto-detection is disabled",
+		},
+		{
+			name:   "empty name",
+			config: "[user]\n\tname =\n\temail = ben@example.com\n",
+			err:    "empty ident name (for <ben@example.com>) not allowed",
+		},
+		{
+			name:   "crud name",
+			config: "[user]\n\tname = \"<>\"\n\temail = ben@example.com\n",
+			err:    "name consists only of disallowed characters: <>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sections, err := Parse([]byte(test.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			conf := &Config{Layers: []*Layer{{Sections: sections}}}
+
+			l := &Loader{Env: testEnv(test.env)}
+			resolve := l.Author
+			if test.committer {
+				resolve = l.Committer
+			}
+
+			id, err := resolve(conf)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Errorf("want error %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *id != test.want {
+				t.Errorf("want %+v, got %+v", test.want, *id)
+			}
+		})
+	}
+}
+
+func TestIdentityBogusHost(t *testing.T) {
+	currentUser = func() (*user.User, error) { return &user.User{Username: "ben", Name: "Ben"}, nil }
+	hostname = func() (string, error) { return "laptop", nil }
+	canonicalHost = func(string) (string, error) { return "", errors.New("no such host") }
+	defer func() {
+		currentUser, hostname = user.Current, defaultHostname
+		canonicalHost = defaultCanonicalHost
+	}()
+
+	_, err := (&Loader{Env: testEnv(nil)}).Author(&Config{})
+	if want := "unable to auto-detect email address (got 'ben@laptop.(none)')"; err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,284 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth is the number of nested includes git follows.
+const maxIncludeDepth = 10
+
+// include splices the files named by the include.path and matching
+// includeIf.<condition>.path values in sections in after the section that
+// includes them. from is the path of the file the sections were read from,
+// or empty for the command line, and stack holds the files currently being
+// included.
+func (l *Loader) include(sections []*Section, from string, stack []string) ([]*Section, error) {
+	var out []*Section
+	for _, s := range sections {
+		out = append(out, s)
+
+		path, ok := s.Values["path"]
+		if !ok {
+			continue
+		}
+		switch s.Type {
+		case "include":
+			if s.ID != "" {
+				continue
+			}
+		case "includeif":
+			match, err := l.includeCondition(s.ID, from)
+			if err != nil {
+				return nil, err
+			}
+			if !match {
+				continue
+			}
+		default:
+			continue
+		}
+
+		included, err := l.includeFile(path, from, stack)
+		if err != nil {
+			return nil, err
+		}
+		if s.Type == "includeif" && l.unconditional && len(remoteURLs(included)) > 0 {
+			return nil, errors.New("remote URLs cannot be configured in file directly or indirectly included by includeIf.hasconfig:remote.*.url")
+		}
+		out = append(out, included...)
+	}
+	return out, nil
+}
+
+func (l *Loader) includeFile(path, from string, stack []string) ([]*Section, error) {
+	expanded, err := l.expandPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not expand include path '%s': %w", path, err)
+	}
+	path = expanded
+
+	if !filepath.IsAbs(path) {
+		if from == "" {
+			return nil, errors.New("relative config includes must come from files")
+		}
+		path = filepath.Join(filepath.Dir(from), path)
+	}
+	path = filepath.Clean(path)
+
+	for _, p := range stack {
+		if p == path {
+			return nil, fmt.Errorf("include cycle detected while including\n\t%s\nfrom\n\t%s", path, from)
+		}
+	}
+
+	sections, err := l.readFile(path)
+	if err != nil || sections == nil {
+		return nil, err
+	}
+
+	if len(stack) > maxIncludeDepth {
+		if from == "" {
+			from = "the command line"
+		}
+		return nil, fmt.Errorf("exceeded maximum include depth (%d) while including\n\t%s\nfrom\n\t%s\nThis might be due to circular includes.", maxIncludeDepth, path, from)
+	}
+	return l.include(sections, path, append(stack, path))
+}
+
+// includeCondition reports whether the includeIf condition cond holds for
+// the repository being loaded. Unknown conditions never hold.
+func (l *Loader) includeCondition(cond, from string) (bool, error) {
+	switch {
+	case strings.HasPrefix(cond, "gitdir:"):
+		return l.includeByGitDir(cond[len("gitdir:"):], from, false)
+	case strings.HasPrefix(cond, "gitdir/i:"):
+		return l.includeByGitDir(cond[len("gitdir/i:"):], from, true)
+	case strings.HasPrefix(cond, "onbranch:"):
+		return l.includeByBranch(cond[len("onbranch:"):])
+	case strings.HasPrefix(cond, "hasconfig:remote.*.url:"):
+		return l.includeByRemoteURL(cond[len("hasconfig:remote.*.url:"):])
+	}
+	return false, nil
+}
+
+// includeByGitDir matches pattern against the git directory, first with
+// its symlinks resolved and then as an unresolved absolute path.
+func (l *Loader) includeByGitDir(pattern, from string, icase bool) (bool, error) {
+	if l.GitDir == "" {
+		return false, nil
+	}
+
+	pattern, prefix, err := l.conditionPattern(pattern, from)
+	if err != nil {
+		return false, err
+	}
+
+	flags := wmPathname
+	if icase {
+		flags |= wmCaseFold
+	}
+
+	abs, err := filepath.Abs(l.GitDir)
+	if err != nil {
+		return false, err
+	}
+	real, err := l.fileSystem().EvalSymlinks(abs)
+	if err != nil {
+		real = abs
+	}
+
+	for _, dir := range []string{real, abs} {
+		dir = filepath.ToSlash(dir)
+		if len(dir) < prefix {
+			continue
+		}
+		if icase && !strings.EqualFold(pattern[:prefix], dir[:prefix]) {
+			continue
+		}
+		if !icase && pattern[:prefix] != dir[:prefix] {
+			continue
+		}
+		if wildmatch(pattern[prefix:], dir[prefix:], flags) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// includeByBranch matches pattern against the name of the branch checked
+// out in the repository. A detached HEAD is not on any branch.
+func (l *Loader) includeByBranch(pattern string) (bool, error) {
+	if l.GitDir == "" {
+		return false, nil
+	}
+
+	ref, err := l.headRef()
+	if err != nil || !strings.HasPrefix(ref, "refs/heads/") {
+		return false, err
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return wildmatch(pattern, strings.TrimPrefix(ref, "refs/heads/"), wmPathname), nil
+}
+
+// includeByRemoteURL matches pattern against the URLs of the remotes
+// configured anywhere in the source being loaded. As in paths, only "**"
+// matches across slashes.
+func (l *Loader) includeByRemoteURL(pattern string) (bool, error) {
+	if l.unconditional {
+		return true, nil
+	}
+
+	if l.remoteURLs == nil {
+		urls, err := l.collectRemoteURLs()
+		if err != nil {
+			return false, err
+		}
+		l.remoteURLs = &urls
+	}
+
+	for _, url := range *l.remoteURLs {
+		if wildmatch(pattern, url, wmPathname) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// collectRemoteURLs reads the source again with every
+// hasconfig:remote.*.url condition holding, and returns the remote URLs
+// it configures. Files included by an includeIf during this pass may not
+// configure remote URLs themselves.
+func (l *Loader) collectRemoteURLs() ([]string, error) {
+	c := *l
+	c.unconditional = true
+
+	if c.source != "" {
+		layer, err := c.loadFile(ScopeUnknown, c.source)
+		if err != nil || layer == nil {
+			return nil, err
+		}
+		return remoteURLs(layer.Sections), nil
+	}
+
+	conf, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	return remoteURLs(conf.Sections()), nil
+}
+
+// remoteURLs returns the remote.<name>.url values in sections.
+func remoteURLs(sections []*Section) []string {
+	var urls []string
+	for _, s := range sections {
+		if url, ok := s.Values["url"]; ok && s.Type == "remote" && s.ID != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// conditionPattern prepares an includeIf pattern the way git does: "~" is
+// expanded, a leading "./" is relative to the directory of the including
+// file, a relative pattern matches at any depth, and a trailing "/"
+// matches everything below it. prefix is the length of the leading part
+// of the pattern that must match literally.
+func (l *Loader) conditionPattern(pattern, from string) (string, int, error) {
+	expanded, err := l.expandPath(pattern)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not expand include path '%s': %w", pattern, err)
+	}
+	pattern = filepath.ToSlash(expanded)
+
+	var prefix int
+	switch {
+	case strings.HasPrefix(pattern, "./"):
+		if from == "" {
+			return "", 0, errors.New("relative config include conditionals must come from files")
+		}
+		dir, err := l.fileSystem().EvalSymlinks(filepath.Dir(from))
+		if err != nil {
+			dir = filepath.Dir(from)
+		}
+		dir = filepath.ToSlash(dir)
+		pattern = dir + pattern[1:]
+		prefix = len(dir) + 1
+	case !filepath.IsAbs(filepath.FromSlash(pattern)):
+		pattern = "**/" + pattern
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return pattern, prefix, nil
+}
+
+// expandPath expands a leading "~/" or "~user/" the way git interpolates
+// paths in config values. The rest of the path is kept as is, so a
+// trailing slash survives.
+func (l *Loader) expandPath(path string) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+
+	name, rest, _ := strings.Cut(path[1:], "/")
+	if name == "" {
+		home := l.getenv("HOME")
+		if home == "" {
+			return "", errors.New("$HOME not set")
+		}
+		return strings.TrimSuffix(home, "/") + "/" + rest, nil
+	}
+
+	home, err := l.fileSystem().HomeDir(name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(home, "/") + "/" + rest, nil
+}
@@ -0,0 +1,276 @@
+package gitconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadFileInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"home/.gitconfig": `[user]
+	name = Ben Burkert
+[include]
+	path = .github/.gitconfig
+	path = missing.gitconfig
+	path = ~/shared/aliases
+[user]
+	email = ben@benburkert.com
+`,
+		"home/.github/.gitconfig": "[user]\n\temail = github@example.com\n\tname = GitHub\n[include]\n\tpath = ../shared/aliases\n",
+		"home/shared/aliases":     "[alias]\n\tst = status\n",
+	})
+
+	l := &Loader{Env: testEnv(map[string]string{"HOME": filepath.Join(dir, "home")})}
+	layer, err := l.LoadFile(ScopeGlobal, filepath.Join(dir, "home", ".gitconfig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{Layers: []*Layer{layer}}
+
+	values := map[string][]string{
+		"user.name":    {"Ben Burkert", "GitHub"},
+		"user.email":   {"github@example.com", "ben@benburkert.com"},
+		"alias.st":     {"status", "status"},
+		"include.path": {".github/.gitconfig", "../shared/aliases", "missing.gitconfig", "~/shared/aliases"},
+	}
+	for key, want := range values {
+		if got := conf.GetAll(key); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %s values %q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestLoadFileIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a": "[include]\n\tpath = b\n",
+		"b": "[include]\n\tpath = a\n",
+	})
+
+	_, err := (&Loader{}).LoadFile(ScopeLocal, filepath.Join(dir, "a"))
+	if err == nil || !strings.Contains(err.Error(), "include cycle detected") {
+		t.Errorf("want include cycle error, got %v", err)
+	}
+}
+
+func TestLoadFileIncludeDepth(t *testing.T) {
+	dir := t.TempDir()
+	files := make(map[string]string)
+	for i := 0; i <= maxIncludeDepth+1; i++ {
+		files[fmt.Sprint(i)] = fmt.Sprintf("[test]\n\tdepth = %d\n[include]\n\tpath = %d\n", i, i+1)
+	}
+	writeFiles(t, dir, files)
+
+	l := &Loader{}
+	layer, err := l.LoadFile(ScopeLocal, filepath.Join(dir, "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := (&Config{Layers: []*Layer{layer}}).Get("test.depth"); got != fmt.Sprint(maxIncludeDepth+1) {
+		t.Errorf("want test.depth = %d, got %s", maxIncludeDepth+1, got)
+	}
+
+	_, err = l.LoadFile(ScopeLocal, filepath.Join(dir, "0"))
+	if err == nil || !strings.Contains(err.Error(), "exceeded maximum include depth (10)") {
+		t.Errorf("want include depth error, got %v", err)
+	}
+}
+
+func TestLoaderParameterInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"extra": "[core]\n\teditor = vim\n"})
+
+	l := &Loader{Env: testEnv(nil)}
+	if err := l.AddParameter("include.path=" + filepath.Join(dir, "extra")); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := conf.Get("core.editor"); got != "vim" {
+		t.Errorf("want core.editor = vim, got %q", got)
+	}
+
+	l = &Loader{Env: testEnv(nil)}
+	if err := l.AddParameter("include.path=extra"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Load(); err == nil || err.Error() != "relative config includes must come from files" {
+		t.Errorf("want relative include error, got %v", err)
+	}
+}
+
+func TestLoadFileIncludeIfGitDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"home/.gitconfig": `[includeIf "gitdir:~/work/"]
+	path = work.gitconfig
+[includeIf "gitdir/i:~/WORK/"]
+	path = work-icase.gitconfig
+[includeIf "gitdir:work/app/.git"]
+	path = app.gitconfig
+[includeIf "gitdir:./work/**/.git"]
+	path = relative.gitconfig
+[includeIf "gitdir:~/personal/"]
+	path = personal.gitconfig
+[includeIf "unknown:~/"]
+	path = personal.gitconfig
+`,
+		"home/work.gitconfig":       "[test]\n\tvalue = work\n",
+		"home/work-icase.gitconfig": "[test]\n\tvalue = work-icase\n",
+		"home/app.gitconfig":        "[test]\n\tvalue = app\n",
+		"home/relative.gitconfig":   "[test]\n\tvalue = relative\n",
+		"home/personal.gitconfig":   "[test]\n\tvalue = personal\n",
+		"home/work/app/.git/HEAD":   "ref: refs/heads/main\n",
+	})
+	home := filepath.Join(dir, "home")
+	if err := os.Symlink(filepath.Join(home, "work"), filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"work", "work-icase", "app", "relative"}
+	for _, gitDir := range []string{
+		filepath.Join(home, "work", "app", ".git"),
+		filepath.Join(dir, "link", "app", ".git"),
+	} {
+		l := &Loader{GitDir: gitDir, Env: testEnv(map[string]string{"HOME": home})}
+		layer, err := l.LoadFile(ScopeGlobal, filepath.Join(home, ".gitconfig"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := (&Config{Layers: []*Layer{layer}}).GetAll("test.value"); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: want test.value %q, got %q", gitDir, want, got)
+		}
+	}
+
+	// without a repository no gitdir condition holds.
+	l := &Loader{Env: testEnv(map[string]string{"HOME": home})}
+	layer, err := l.LoadFile(ScopeGlobal, filepath.Join(home, ".gitconfig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (&Config{Layers: []*Layer{layer}}).GetAll("test.value"); len(got) != 0 {
+		t.Errorf("want no test.value, got %q", got)
+	}
+}
+
+func TestLoaderParameterIncludeIfRelative(t *testing.T) {
+	l := &Loader{GitDir: t.TempDir(), Env: testEnv(nil)}
+	if err := l.AddParameter("includeIf.gitdir:./.path=extra"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Load(); err == nil || err.Error() != "relative config include conditionals must come from files" {
+		t.Errorf("want relative include conditional error, got %v", err)
+	}
+}
+
+func TestLoadFileIncludeIfOnBranch(t *testing.T) {
+	const config = `[includeIf "onbranch:release/**"]
+	path = release.gitconfig
+[includeIf "onbranch:feature/"]
+	path = feature.gitconfig
+[includeIf "onbranch:main"]
+	path = main.gitconfig
+`
+	tests := []struct {
+		files map[string]string
+		want  []string
+	}{
+		{map[string]string{"HEAD": "ref: refs/heads/main\n"}, []string{"main"}},
+		{map[string]string{"HEAD": "ref: refs/heads/release/1.0\n"}, []string{"release"}},
+		{map[string]string{"HEAD": "ref: refs/heads/feature/a/b\n"}, []string{"feature"}},
+		{map[string]string{"HEAD": "ref: refs/heads/mainline\n"}, nil},
+		{map[string]string{"HEAD": "0123456789abcdef0123456789abcdef01234567\n"}, nil},
+		{map[string]string{"HEAD": "ref: refs/remotes/origin/main\n"}, nil},
+		{map[string]string{
+			"HEAD":               "ref: refs/heads/current\n",
+			"refs/heads/current": "ref: refs/heads/release/2.0\n",
+		}, []string{"release"}},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		test.files["config"] = config
+		test.files["release.gitconfig"] = "[test]\n\tvalue = release\n"
+		test.files["feature.gitconfig"] = "[test]\n\tvalue = feature\n"
+		test.files["main.gitconfig"] = "[test]\n\tvalue = main\n"
+		writeFiles(t, dir, test.files)
+
+		l := &Loader{GitDir: dir, Env: testEnv(nil)}
+		layer, err := l.LoadFile(ScopeLocal, filepath.Join(dir, "config"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := (&Config{Layers: []*Layer{layer}}).GetAll("test.value"); !reflect.DeepEqual(test.want, got) {
+			t.Errorf("HEAD %q: want test.value %q, got %q", test.files["HEAD"], test.want, got)
+		}
+	}
+}
+
+func TestLoadIncludeIfHasConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"home/.gitconfig": `[includeIf "hasconfig:remote.*.url:https://github.com/**"]
+	path = github.gitconfig
+[includeIf "hasconfig:remote.*.url:*://gitlab.example.com/**"]
+	path = gitlab.gitconfig
+[includeIf "hasconfig:remote.*.url:https://github.com/*"]
+	path = shallow.gitconfig
+[includeIf "hasconfig:remote.*.url:https://github.com/benburkert/*"]
+	path = owner.gitconfig
+`,
+		"home/github.gitconfig":  "[user]\n\temail = me@github.example.com\n",
+		"home/shallow.gitconfig": "[foo]\n\tbar = yes\n",
+		"home/owner.gitconfig":   "[foo]\n\towner = yes\n",
+		"home/gitlab.gitconfig":  "[user]\n\temail = me@gitlab.example.com\n",
+		"repo/.git/config":       "[remote \"origin\"]\n\turl = https://github.com/benburkert/go-gitconfig\n",
+	})
+
+	l := &Loader{
+		GitDir: filepath.Join(dir, "repo", ".git"),
+		Env:    testEnv(map[string]string{"HOME": filepath.Join(dir, "home")}),
+	}
+	conf, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"me@github.example.com"}, conf.GetAll("user.email"); !reflect.DeepEqual(want, got) {
+		t.Errorf("want user.email %q, got %q", want, got)
+	}
+	// as in gitdir conditions, only "**" matches across slashes.
+	if got, ok := conf.Get("foo.bar"); ok {
+		t.Errorf("want no foo.bar, got %q", got)
+	}
+	if got, _ := conf.Get("foo.owner"); got != "yes" {
+		t.Errorf("want foo.owner = yes, got %q", got)
+	}
+
+	// a single file is matched against its own remotes.
+	layer, err := l.LoadFile(ScopeGlobal, filepath.Join(dir, "home", ".gitconfig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (&Config{Layers: []*Layer{layer}}).GetAll("user.email"); len(got) != 0 {
+		t.Errorf("want no user.email, got %q", got)
+	}
+}
+
+func TestLoadIncludeIfHasConfigRemoteURL(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config":   "[includeIf \"hasconfig:remote.*.url:https://**\"]\n\tpath = included\n",
+		"included": "[include]\n\tpath = remote\n",
+		"remote":   "[remote \"origin\"]\n\turl = https://example.com/repo\n",
+	})
+
+	_, err := (&Loader{}).LoadFile(ScopeLocal, filepath.Join(dir, "config"))
+	if err == nil || !strings.HasPrefix(err.Error(), "remote URLs cannot be configured in file directly or indirectly included") {
+		t.Errorf("want remote URL error, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package gitconfig
+
+// A Direction says whether a URL is fetched from or pushed to.
+type Direction int
+
+const (
+	DirectionFetch Direction = iota
+	DirectionPush
+)
+
+// RewriteURL rewrites url with the url.<base>.insteadOf values: the base
+// whose insteadOf value is the longest prefix of url replaces that prefix.
+// Several insteadOf values may share one base. URLs pushed to are first
+// rewritten with url.<base>.pushInsteadOf, and only fall back to
+// insteadOf if no pushInsteadOf value matches.
+func (c *Config) RewriteURL(url string, dir Direction) string {
+	if dir == DirectionPush {
+		if pushURL := rewriteURL(c.urlRewrites("pushinsteadof"), url); pushURL != url {
+			return pushURL
+		}
+	}
+	return rewriteURL(c.urlRewrites("insteadof"), url)
+}
+
+// A urlRewrite is a url.<base> section: URLs that start with one of its
+// prefixes are rewritten to start with base instead.
+type urlRewrite struct {
+	base     string
+	prefixes []string
+}
+
+// urlRewrites returns the url.<base>.<key> rewrites of c, where key is
+// "insteadof" or "pushinsteadof", in the order the bases first appear.
+func (c *Config) urlRewrites(key string) []*urlRewrite {
+	var (
+		rewrites []*urlRewrite
+		byBase   = make(map[string]*urlRewrite)
+	)
+	for _, s := range c.Sections() {
+		prefix, ok := s.Values[key]
+		if s.Type != "url" || !ok {
+			continue
+		}
+
+		rw, ok := byBase[s.ID]
+		if !ok {
+			rw = &urlRewrite{base: s.ID}
+			byBase[s.ID] = rw
+			rewrites = append(rewrites, rw)
+		}
+		rw.prefixes = append(rw.prefixes, prefix)
+	}
+	return rewrites
+}
+
+// rewriteURL applies the rewrite with the longest matching prefix to url.
+// Of equally long prefixes, the first wins.
+func rewriteURL(rewrites []*urlRewrite, url string) string {
+	var (
+		longest *urlRewrite
+		length  int
+	)
+	for _, rw := range rewrites {
+		for _, prefix := range rw.prefixes {
+			if len(url) >= len(prefix) && url[:len(prefix)] == prefix && (longest == nil || len(prefix) > length) {
+				longest, length = rw, len(prefix)
+			}
+		}
+	}
+
+	if longest == nil {
+		return url
+	}
+	return longest.base + url[length:]
+}
@@ -0,0 +1,260 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// A Loader reads the configuration files of every scope in the order git
+// reads them:
+//
+//	system    $(prefix)/etc/gitconfig
+//	global    $XDG_CONFIG_HOME/git/config, then ~/.gitconfig
+//	local     $GIT_COMMON_DIR/config
+//	worktree  $GIT_DIR/config.worktree, if extensions.worktreeConfig is set
+//
+// Files that do not exist are skipped, and [include] and [includeIf]
+// sections are followed. The GIT_CONFIG_SYSTEM, GIT_CONFIG_NOSYSTEM and
+// GIT_CONFIG_GLOBAL environment variables change which files are read,
+// and GIT_CONFIG_COUNT and GIT_CONFIG_PARAMETERS add a command scope on
+// top, followed by the overrides of AddParameter and AddConfigEnv.
+type Loader struct {
+	// Prefix is the installation prefix of git, "/usr" if empty. As in
+	// git, the system config of the /usr prefix is /etc/gitconfig.
+	Prefix string
+
+	// GitDir is the git directory of the repository. The local and
+	// worktree scopes are skipped if it is empty.
+	GitDir string
+
+	// CommonDir is the directory shared by every worktree of the
+	// repository, which holds the local config. It defaults to GitDir,
+	// and differs from it in linked worktrees.
+	CommonDir string
+
+	// Env looks up environment variables. It defaults to os.LookupEnv.
+	Env func(key string) (string, bool)
+
+	// FS is where files are read from. It defaults to OSFileSystem.
+	FS FileSystem
+
+	// parameters are the overrides added by AddParameter and AddConfigEnv.
+	parameters []*Section
+
+	// source is the file being loaded by LoadFile, or empty for every
+	// scope. It is what hasconfig:remote.*.url conditions are matched
+	// against.
+	source string
+
+	// remoteURLs are the remote.*.url values of source, collected the
+	// first time a hasconfig:remote.*.url condition is checked.
+	remoteURLs *[]string
+
+	// unconditional is set while collecting remoteURLs, when every
+	// hasconfig:remote.*.url condition holds.
+	unconditional bool
+}
+
+// Load reads the configuration of the repository at gitDir, which may be
+// empty to read only the system and global scopes.
+func Load(gitDir string) (*Config, error) {
+	return (&Loader{GitDir: gitDir}).Load()
+}
+
+// Load reads the configuration of every scope.
+func (l *Loader) Load() (*Config, error) {
+	ll := *l
+	ll.source, ll.remoteURLs = "", nil
+	return ll.load()
+}
+
+func (l *Loader) load() (*Config, error) {
+	conf := &Config{}
+	add := func(scope Scope, path string) error {
+		layer, err := l.loadFile(scope, path)
+		if err != nil || layer == nil {
+			return err
+		}
+		conf.Layers = append(conf.Layers, layer)
+		return nil
+	}
+
+	nosystem, err := l.envBool("GIT_CONFIG_NOSYSTEM")
+	if err != nil {
+		return nil, err
+	}
+	if !nosystem {
+		if err := add(ScopeSystem, l.systemPath()); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range l.globalPaths() {
+		if err := add(ScopeGlobal, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.loadRepository(conf); err != nil {
+		return nil, err
+	}
+
+	command, err := l.commandLayer()
+	if err != nil {
+		return nil, err
+	}
+	if len(command.Sections) > 0 {
+		conf.Layers = append(conf.Layers, command)
+	}
+	return conf, nil
+}
+
+// loadRepository adds the local and worktree scopes to conf.
+func (l *Loader) loadRepository(conf *Config) error {
+	if l.GitDir == "" {
+		return nil
+	}
+
+	local, err := l.loadFile(ScopeLocal, filepath.Join(l.commonDir(), "config"))
+	if err != nil || local == nil {
+		return err
+	}
+	conf.Layers = append(conf.Layers, local)
+
+	worktreeConfig, err := (&Config{Layers: []*Layer{local}}).Bool("extensions.worktreeConfig", false)
+	if err != nil || !worktreeConfig {
+		return err
+	}
+
+	worktree, err := l.loadFile(ScopeWorktree, filepath.Join(l.GitDir, "config.worktree"))
+	if err != nil || worktree == nil {
+		return err
+	}
+	conf.Layers = append(conf.Layers, worktree)
+	return nil
+}
+
+func (l *Loader) commonDir() string {
+	if l.CommonDir == "" {
+		return l.GitDir
+	}
+	return l.CommonDir
+}
+
+// LoadFile reads the config file at path as a layer of the given scope,
+// following its includes. It returns a nil layer if the file does not
+// exist.
+func (l *Loader) LoadFile(scope Scope, path string) (*Layer, error) {
+	ll := *l
+	ll.source, ll.remoteURLs = path, nil
+	return ll.loadFile(scope, path)
+}
+
+func (l *Loader) loadFile(scope Scope, path string) (*Layer, error) {
+	sections, err := l.readFile(path)
+	if err != nil || sections == nil {
+		return nil, err
+	}
+
+	if sections, err = l.include(sections, path, []string{filepath.Clean(path)}); err != nil {
+		return nil, err
+	}
+	return &Layer{
+		Scope:    scope,
+		Path:     path,
+		Sections: sections,
+	}, nil
+}
+
+// readFile parses the config file at path. It returns nil sections if the
+// file does not exist.
+func (l *Loader) readFile(path string) ([]*Section, error) {
+	data, err := l.fileSystem().ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to access '%s': %w", path, err)
+	}
+
+	sections, err := Parse(data)
+	if err != nil {
+		var serr *SyntaxError
+		if errors.As(err, &serr) {
+			return nil, fmt.Errorf("%w in file %s", err, path)
+		}
+		return nil, fmt.Errorf("bad config file '%s': %w", path, err)
+	}
+	if sections == nil {
+		sections = []*Section{}
+	}
+	return sections, nil
+}
+
+func (l *Loader) systemPath() string {
+	if path, ok := l.lookupEnv("GIT_CONFIG_SYSTEM"); ok {
+		return path
+	}
+
+	switch l.Prefix {
+	case "", "/usr":
+		return "/etc/gitconfig"
+	default:
+		return filepath.Join(l.Prefix, "etc", "gitconfig")
+	}
+}
+
+// globalPaths returns the XDG and home directory config paths, lowest
+// precedence first.
+func (l *Loader) globalPaths() []string {
+	if path, ok := l.lookupEnv("GIT_CONFIG_GLOBAL"); ok {
+		return []string{path}
+	}
+
+	var paths []string
+
+	home := l.getenv("HOME")
+	if xdg := l.getenv("XDG_CONFIG_HOME"); xdg != "" {
+		paths = append(paths, filepath.Join(xdg, "git", "config"))
+	} else if home != "" {
+		paths = append(paths, filepath.Join(home, ".config", "git", "config"))
+	}
+	if home != "" {
+		paths = append(paths, filepath.Join(home, ".gitconfig"))
+	}
+	return paths
+}
+
+func (l *Loader) fileSystem() FileSystem {
+	if l.FS == nil {
+		return OSFileSystem
+	}
+	return l.FS
+}
+
+func (l *Loader) lookupEnv(key string) (string, bool) {
+	if l.Env == nil {
+		return os.LookupEnv(key)
+	}
+	return l.Env(key)
+}
+
+func (l *Loader) getenv(key string) string {
+	value, _ := l.lookupEnv(key)
+	return value
+}
+
+func (l *Loader) envBool(key string) (bool, error) {
+	value, ok := l.lookupEnv(key)
+	if !ok {
+		return false, nil
+	}
+	b, err := ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("bad boolean environment value '%s' for '%s'", value, key)
+	}
+	return b, nil
+}
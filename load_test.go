@@ -0,0 +1,112 @@
+package gitconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"prefix/etc/gitconfig":      "[core]\n\teditor = ed\n\tpager = more\n[alias]\n\ts = status\n",
+		"xdg/git/config":            "[core]\n\teditor = vi\n",
+		"home/.gitconfig":           "[core]\n\teditor = vim\n[alias]\n\ts = status -s\n",
+		"repo/.git/config":          "[core]\n\tpager = less\n[extensions]\n\tworktreeConfig = true\n",
+		"repo/.git/config.worktree": "[core]\n\tsparseCheckout = true\n",
+	})
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+
+	l := &Loader{
+		Prefix: filepath.Join(dir, "prefix"),
+		GitDir: filepath.Join(dir, "repo", ".git"),
+	}
+	conf, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var scopes []Scope
+	for _, layer := range conf.Layers {
+		scopes = append(scopes, layer.Scope)
+	}
+	want := []Scope{ScopeSystem, ScopeGlobal, ScopeGlobal, ScopeLocal, ScopeWorktree}
+	if !reflect.DeepEqual(want, scopes) {
+		t.Errorf("want scopes %v, got %v", want, scopes)
+	}
+	if want, got := filepath.Join(dir, "xdg", "git", "config"), conf.Layers[1].Path; want != got {
+		t.Errorf("want XDG layer path %q, got %q", want, got)
+	}
+
+	values := map[string]string{
+		"core.editor":         "vim",
+		"core.pager":          "less",
+		"core.sparsecheckout": "true",
+		"alias.s":             "status -s",
+		"Alias.S":             "status -s",
+	}
+	for key, want := range values {
+		if got, ok := conf.Get(key); !ok || want != got {
+			t.Errorf("want %s = %q, got %q", key, want, got)
+		}
+	}
+
+	if want, got := []string{"ed", "vi", "vim"}, conf.GetAll("core.editor"); !reflect.DeepEqual(want, got) {
+		t.Errorf("want core.editor values %q, got %q", want, got)
+	}
+}
+
+func TestLoadWorktreeConfigDisabled(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".git/config":          "[core]\n\tbare = false\n",
+		".git/config.worktree": "[core]\n\tbare = true\n",
+	})
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	l := &Loader{
+		Prefix: filepath.Join(dir, "prefix"),
+		GitDir: filepath.Join(dir, ".git"),
+	}
+	conf, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 1, len(conf.Layers); want != got {
+		t.Errorf("want %d layers, got %d", want, got)
+	}
+	if got, _ := conf.Get("core.bare"); got != "false" {
+		t.Errorf("want core.bare = false, got %q", got)
+	}
+}
+
+func TestLoadSyntaxError(t *testing.T) {
+	dir := t.TempDir()
+	global := filepath.Join(dir, "home", ".gitconfig")
+	writeFiles(t, dir, map[string]string{
+		"home/.gitconfig": "[core]\n\tbare\n\teditor = vim\n[user\n",
+	})
+
+	l := &Loader{Env: testEnv(map[string]string{"HOME": filepath.Join(dir, "home"), "GIT_CONFIG_NOSYSTEM": "1"})}
+	_, err := l.Load()
+	if want := "bad config line 4 in file " + global; err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,533 @@
+package gitconfig
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Object types, as numbered in packfiles.
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+var objectTypes = map[string]int{
+	"commit": objCommit,
+	"tree":   objTree,
+	"blob":   objBlob,
+	"tag":    objTag,
+}
+
+// maxDeltaDepth bounds the chains of deltas followed in a packfile.
+const maxDeltaDepth = 10000
+
+// An objectStore reads objects from the loose object directories and
+// packfiles of a repository. Packfiles are opened once an object is found
+// in their index, and only the objects that are needed are read from
+// them.
+type objectStore struct {
+	fs       FileSystem
+	dirs     []string // the objects directory, then its alternates
+	hashSize int
+
+	packs       []*packFile
+	packsLoaded bool
+}
+
+type packFile struct {
+	path string
+	idx  []byte
+
+	file fs.File     // nil until the packfile is opened
+	data io.ReaderAt // the contents of file
+	size int64
+}
+
+// objectStore returns the object store of the repository. Objects are
+// named by SHA-1, or SHA-256 if extensions.objectFormat says so.
+func (l *Loader) objectStore() (*objectStore, error) {
+	sections, err := l.readFile(filepath.Join(l.commonDir(), "config"))
+	if err != nil {
+		return nil, err
+	}
+	conf := &Config{Layers: []*Layer{{Scope: ScopeLocal, Sections: sections}}}
+
+	store := &objectStore{fs: l.fileSystem(), hashSize: 20}
+	switch format, _ := conf.Get("extensions.objectformat"); strings.ToLower(format) {
+	case "", "sha1":
+	case "sha256":
+		store.hashSize = 32
+	default:
+		return nil, fmt.Errorf("unknown repository object format '%s'", format)
+	}
+
+	dir := filepath.Join(l.commonDir(), "objects")
+	if env, ok := l.lookupEnv("GIT_OBJECT_DIRECTORY"); ok {
+		dir = env
+	}
+	store.addDir(dir, 0)
+	return store, nil
+}
+
+// addDir adds the objects directory dir and, recursively, the alternates
+// listed in its info/alternates file.
+func (s *objectStore) addDir(dir string, depth int) {
+	for _, d := range s.dirs {
+		if d == dir {
+			return
+		}
+	}
+	s.dirs = append(s.dirs, dir)
+
+	data, err := s.fs.ReadFile(filepath.Join(dir, "info", "alternates"))
+	if err != nil || depth >= 5 {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dir, line)
+		}
+		s.addDir(filepath.Clean(line), depth+1)
+	}
+}
+
+// readObject returns the type and contents of the object named id, a hex
+// object name.
+func (s *objectStore) readObject(id string) (int, []byte, error) {
+	return s.readObjectDepth(id, 0)
+}
+
+func (s *objectStore) readObjectDepth(id string, depth int) (int, []byte, error) {
+	raw, err := hex.DecodeString(id)
+	if err != nil || len(raw) != s.hashSize {
+		return 0, nil, fmt.Errorf("invalid object name %s", id)
+	}
+
+	for _, dir := range s.dirs {
+		typ, data, err := s.readLoose(filepath.Join(dir, id[:2], id[2:]))
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return typ, data, err
+		}
+	}
+
+	if err := s.loadPacks(); err != nil {
+		return 0, nil, err
+	}
+	for _, p := range s.packs {
+		offset, ok, err := p.find(raw)
+		if err != nil {
+			return 0, nil, err
+		}
+		if ok {
+			return s.readPacked(p, offset, depth)
+		}
+	}
+	return 0, nil, fmt.Errorf("object %s not found", id)
+}
+
+// readLoose reads a zlib compressed "<type> <size>\x00<contents>" object.
+func (s *objectStore) readLoose(path string) (int, []byte, error) {
+	compressed, err := s.fs.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, syscall.ENOTDIR) {
+			err = fs.ErrNotExist
+		}
+		return 0, nil, err
+	}
+
+	data, err := inflate(compressed, -1)
+	if err != nil {
+		return 0, nil, fmt.Errorf("corrupt loose object %s: %w", path, err)
+	}
+
+	header, contents, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return 0, nil, fmt.Errorf("corrupt loose object %s: missing header", path)
+	}
+	name, size, _ := strings.Cut(string(header), " ")
+	typ, ok := objectTypes[name]
+	if n, err := strconv.Atoi(size); !ok || err != nil || n != len(contents) {
+		return 0, nil, fmt.Errorf("corrupt loose object %s: bad header %q", path, header)
+	}
+	return typ, contents, nil
+}
+
+// loadPacks reads the index of every packfile in the object directories.
+func (s *objectStore) loadPacks() error {
+	if s.packsLoaded {
+		return nil
+	}
+	s.packsLoaded = true
+
+	for _, dir := range s.dirs {
+		entries, err := s.fs.ReadDir(filepath.Join(dir, "pack"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), ".idx")
+			if !ok || entry.IsDir() {
+				continue
+			}
+
+			path := filepath.Join(dir, "pack", name)
+			idx, err := s.fs.ReadFile(path + ".idx")
+			if err != nil {
+				return err
+			}
+			s.packs = append(s.packs, &packFile{path: path, idx: idx})
+		}
+	}
+	return nil
+}
+
+// find returns the offset of the object id in the packfile.
+func (p *packFile) find(id []byte) (uint64, bool, error) {
+	bad := fmt.Errorf("bad pack index %s.idx", p.path)
+
+	idx, hashSize := p.idx, len(id)
+	version := 1
+	if bytes.HasPrefix(idx, []byte("\377tOc")) {
+		if len(idx) < 8 || binary.BigEndian.Uint32(idx[4:]) != 2 {
+			return 0, false, bad
+		}
+		version, idx = 2, idx[8:]
+	}
+	if len(idx) < 256*4 {
+		return 0, false, bad
+	}
+
+	fanout := func(i int) int { return int(binary.BigEndian.Uint32(idx[i*4:])) }
+	count := fanout(255)
+	lo, hi := 0, fanout(int(id[0]))
+	if id[0] > 0 {
+		lo = fanout(int(id[0]) - 1)
+	}
+
+	if version == 1 {
+		const entry = 4
+		table := idx[256*4:]
+		if len(table) < count*(entry+hashSize) || lo > hi || hi > count {
+			return 0, false, bad
+		}
+		i := lo + sort.Search(hi-lo, func(i int) bool {
+			off := (lo+i)*(entry+hashSize) + entry
+			return bytes.Compare(table[off:off+hashSize], id) >= 0
+		})
+		if i == hi {
+			return 0, false, nil
+		}
+		off := i * (entry + hashSize)
+		if !bytes.Equal(table[off+entry:off+entry+hashSize], id) {
+			return 0, false, nil
+		}
+		return uint64(binary.BigEndian.Uint32(table[off:])), true, nil
+	}
+
+	names := idx[256*4:]
+	if len(names) < count*(hashSize+8) || lo > hi || hi > count {
+		return 0, false, bad
+	}
+	offsets := names[count*hashSize+count*4:]
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		off := (lo + i) * hashSize
+		return bytes.Compare(names[off:off+hashSize], id) >= 0
+	})
+	if i == hi || !bytes.Equal(names[i*hashSize:(i+1)*hashSize], id) {
+		return 0, false, nil
+	}
+
+	offset := uint64(binary.BigEndian.Uint32(offsets[i*4:]))
+	if offset&0x80000000 != 0 {
+		// an index into the table of 8 byte offsets
+		large := offsets[count*4:]
+		n := int(offset &^ 0x80000000)
+		if len(large) < (n+1)*8 {
+			return 0, false, bad
+		}
+		offset = binary.BigEndian.Uint64(large[n*8:])
+	}
+	return offset, true, nil
+}
+
+// close closes the packfiles that were opened.
+func (s *objectStore) close() error {
+	var errs []error
+	for _, p := range s.packs {
+		if p.file != nil {
+			errs = append(errs, p.file.Close())
+			p.file, p.data = nil, nil
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// open opens the packfile and checks its signature. A file that is not
+// an io.ReaderAt is read into memory.
+func (p *packFile) open(fsys FileSystem) error {
+	if p.file != nil {
+		return nil
+	}
+
+	f, err := fsys.Open(p.path + ".pack")
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	data, ok := f.(io.ReaderAt)
+	if !ok {
+		contents, err := io.ReadAll(f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		data = bytes.NewReader(contents)
+	}
+
+	var header [4]byte
+	if _, err := data.ReadAt(header[:], 0); err != nil || fi.Size() < 12 || string(header[:]) != "PACK" {
+		f.Close()
+		return fmt.Errorf("bad packfile %s.pack", p.path)
+	}
+	p.file, p.data, p.size = f, data, fi.Size()
+	return nil
+}
+
+// maxEntryHeader is the most an object header in a packfile takes: the
+// type and a 64 bit size, then the offset of an ofs-delta base or the
+// name of a ref-delta base.
+const maxEntryHeader = 10 + 32
+
+// readPacked reads the object at offset in the packfile, applying deltas
+// to their base objects.
+func (s *objectStore) readPacked(p *packFile, offset uint64, depth int) (int, []byte, error) {
+	if depth > maxDeltaDepth {
+		return 0, nil, fmt.Errorf("delta chain too long in pack %s.pack", p.path)
+	}
+	if err := p.open(s.fs); err != nil {
+		return 0, nil, err
+	}
+
+	bad := fmt.Errorf("corrupt object at offset %d in pack %s.pack", offset, p.path)
+	if offset < 12 || offset >= uint64(p.size) {
+		return 0, nil, bad
+	}
+
+	data := make([]byte, maxEntryHeader)
+	n, err := p.data.ReadAt(data, int64(offset))
+	if err != nil && !(err == io.EOF && n > 0) {
+		return 0, nil, err
+	}
+	data = data[:n]
+	pos := 0
+
+	c := data[pos]
+	typ, size, shift := int(c>>4)&7, int(c&15), 4
+	for c&0x80 != 0 {
+		if pos++; pos >= len(data) || shift > 56 {
+			return 0, nil, bad
+		}
+		c = data[pos]
+		size |= int(c&0x7f) << shift
+		shift += 7
+	}
+	pos++
+
+	var (
+		baseType int
+		base     []byte
+	)
+	switch typ {
+	case objCommit, objTree, objBlob, objTag:
+	case objOfsDelta:
+		var rel uint64
+		for i := 0; ; i++ {
+			if pos >= len(data) || i > 9 {
+				return 0, nil, bad
+			}
+			c := data[pos]
+			pos++
+			if i > 0 {
+				rel++
+			}
+			rel = rel<<7 | uint64(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		if rel == 0 || rel > offset {
+			return 0, nil, bad
+		}
+		baseType, base, err = s.readPacked(p, offset-rel, depth+1)
+	case objRefDelta:
+		if pos+s.hashSize > len(data) {
+			return 0, nil, bad
+		}
+		id := hex.EncodeToString(data[pos : pos+s.hashSize])
+		pos += s.hashSize
+		baseType, base, err = s.readObjectDepth(id, depth+1)
+	default:
+		return 0, nil, bad
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	compressed := io.NewSectionReader(p.data, int64(offset)+int64(pos), p.size-int64(offset)-int64(pos))
+	contents, err := inflateReader(compressed, size)
+	if err != nil {
+		return 0, nil, fmt.Errorf("corrupt object at offset %d in pack %s.pack: %w", offset, p.path, err)
+	}
+	if base == nil {
+		return typ, contents, nil
+	}
+
+	result, err := applyDelta(base, contents)
+	if err != nil {
+		return 0, nil, fmt.Errorf("corrupt object at offset %d in pack %s.pack: %w", offset, p.path, err)
+	}
+	return baseType, result, nil
+}
+
+// inflate decompresses zlib data, which must be size bytes long unless
+// size is negative.
+func inflate(compressed []byte, size int) ([]byte, error) {
+	return inflateReader(bytes.NewReader(compressed), size)
+}
+
+// inflateReader is inflate for a zlib stream that is read only as far as
+// it goes.
+func inflateReader(compressed io.Reader, size int) ([]byte, error) {
+	zr, err := zlib.NewReader(compressed)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	if size < 0 {
+		return io.ReadAll(zr)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(zr, int64(size)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != size {
+		return nil, errors.New("object size mismatch")
+	}
+	return data, nil
+}
+
+// applyDelta rebuilds an object from its base and a delta of copy and
+// insert instructions.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	srcSize, delta, ok := deltaSize(delta)
+	if !ok || srcSize != len(base) {
+		return nil, errors.New("delta base size mismatch")
+	}
+	dstSize, delta, ok := deltaSize(delta)
+	if !ok {
+		return nil, errors.New("bad delta header")
+	}
+
+	// Each opcode takes at least a byte of the delta, and yields at most
+	// 127 inserted bytes or a copy of no more than its base and the 24 bit
+	// copy size, so a larger result size can only be corrupt.
+	maxOp := min(len(base), 0xffffff)
+	if maxOp < 127 {
+		maxOp = 127
+	}
+	if n := len(delta); n < math.MaxInt/maxOp && dstSize > n*maxOp {
+		return nil, errors.New("delta result size mismatch")
+	}
+
+	var out []byte
+	for len(delta) > 0 {
+		cmd := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case cmd&0x80 != 0:
+			var offset, size int
+			for i := 0; i < 7; i++ {
+				if cmd&(1<<i) == 0 {
+					continue
+				}
+				if len(delta) == 0 {
+					return nil, errors.New("truncated delta")
+				}
+				if i < 4 {
+					offset |= int(delta[0]) << (8 * i)
+				} else {
+					size |= int(delta[0]) << (8 * (i - 4))
+				}
+				delta = delta[1:]
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, errors.New("delta copies past the end of its base")
+			}
+			out = append(out, base[offset:offset+size]...)
+		case cmd != 0:
+			if int(cmd) > len(delta) {
+				return nil, errors.New("truncated delta")
+			}
+			out = append(out, delta[:cmd]...)
+			delta = delta[cmd:]
+		default:
+			return nil, errors.New("unexpected delta opcode 0")
+		}
+		if len(out) > dstSize {
+			return nil, errors.New("delta result size mismatch")
+		}
+	}
+
+	if len(out) != dstSize {
+		return nil, errors.New("delta result size mismatch")
+	}
+	return out, nil
+}
+
+// deltaSize decodes a little endian base 128 size from the start of a
+// delta. Sizes that do not fit in an int are rejected.
+func deltaSize(delta []byte) (int, []byte, bool) {
+	var size, shift int
+	for i, c := range delta {
+		if shift >= strconv.IntSize || int(c&0x7f) > math.MaxInt>>shift {
+			break
+		}
+		size |= int(c&0x7f) << shift
+		shift += 7
+		if c&0x80 == 0 {
+			return size, delta[i+1:], true
+		}
+	}
+	return 0, nil, false
+}
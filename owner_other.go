@@ -0,0 +1,13 @@
+//go:build !unix
+
+package gitconfig
+
+import "os"
+
+func fileOwner(fi os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+func fileDevice(fi os.FileInfo) (dev uint64, ok bool) {
+	return 0, false
+}
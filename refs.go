@@ -0,0 +1,132 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// maxSymrefDepth is the number of symbolic refs git follows.
+const maxSymrefDepth = 5
+
+// headRef follows the chain of symbolic refs starting at HEAD and returns
+// the name of the ref it ends at, which need not exist yet. It returns
+// an empty name if HEAD is missing or detached.
+func (l *Loader) headRef() (string, error) {
+	var ref string
+	name := "HEAD"
+	for i := 0; i <= maxSymrefDepth; i++ {
+		value, ok, err := l.readRef(name)
+		if err != nil || !ok {
+			return ref, err
+		}
+
+		target, ok := strings.CutPrefix(value, "ref:")
+		if !ok {
+			return ref, nil
+		}
+		ref = strings.TrimSpace(target)
+		name = ref
+	}
+	return "", fmt.Errorf("too many levels of symbolic refs at HEAD")
+}
+
+// CurrentBranch returns the short name of the branch checked out in the
+// repository, such as "main", or the empty string if HEAD is detached.
+func (l *Loader) CurrentBranch() (string, error) {
+	ref, err := l.headRef()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(ref, "refs/heads/"), nil
+}
+
+// resolveRef follows the ref name, and any symbolic refs it points to, to
+// an object name.
+func (l *Loader) resolveRef(name string) (string, bool, error) {
+	for i := 0; i <= maxSymrefDepth; i++ {
+		value, ok, err := l.readRef(name)
+		if err != nil || !ok {
+			return "", false, err
+		}
+
+		target, ok := strings.CutPrefix(value, "ref:")
+		if !ok {
+			// FETCH_HEAD and friends have more after the object name.
+			id, _, _ := strings.Cut(value, "\t")
+			return strings.TrimSpace(id), true, nil
+		}
+		name = strings.TrimSpace(target)
+	}
+	return "", false, fmt.Errorf("too many levels of symbolic refs at %s", name)
+}
+
+// readRef returns the first line of the loose ref name, or its object name
+// in packed-refs. Refs outside of refs/, and the refs/worktree/,
+// refs/bisect/ and refs/rewritten/ hierarchies, are private to each
+// worktree, and the others are shared. A one-level name is only a ref if
+// it looks like a pseudo-ref such as HEAD, so that a short name like
+// "config" is not read from the file of that name in the git directory.
+func (l *Loader) readRef(name string) (string, bool, error) {
+	if !strings.Contains(name, "/") && !isPseudoRefSyntax(name) {
+		return "", false, nil
+	}
+
+	dir := l.commonDir()
+	if isPerWorktreeRef(name) {
+		dir = l.GitDir
+	}
+
+	data, err := l.fileSystem().ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	switch {
+	case err == nil:
+		line, _, _ := strings.Cut(string(data), "\n")
+		return strings.TrimRight(line, "\r"), true, nil
+	case !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, syscall.ENOTDIR) && !errors.Is(err, syscall.EISDIR):
+		return "", false, err
+	}
+
+	if dir != l.commonDir() {
+		return "", false, nil
+	}
+	data, err = l.fileSystem().ReadFile(filepath.Join(dir, "packed-refs"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		if id, ref, ok := strings.Cut(strings.TrimRight(line, "\r"), " "); ok && ref == name {
+			return id, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func isPerWorktreeRef(name string) bool {
+	return !strings.HasPrefix(name, "refs/") ||
+		strings.HasPrefix(name, "refs/worktree/") ||
+		strings.HasPrefix(name, "refs/bisect/") ||
+		strings.HasPrefix(name, "refs/rewritten/")
+}
+
+// isPseudoRefSyntax reports whether name is made of only uppercase
+// letters and underscores, like HEAD and FETCH_HEAD.
+func isPseudoRefSyntax(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if (c < 'A' || c > 'Z') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
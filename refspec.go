@@ -0,0 +1,291 @@
+package gitconfig
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// A RefSpec maps refs of one repository to refs of another, such as
+// "+refs/heads/*:refs/remotes/origin/*".
+type RefSpec struct {
+	// Src and Dst are the source and destination. Dst is empty if the
+	// refspec has none. In a pattern, both contain one "*".
+	Src, Dst string
+
+	// Force is set by a leading "+", to update refs even if they are not
+	// fast forwards.
+	Force bool
+
+	// Negative is set by a leading "^", for a refspec that excludes the
+	// refs matching Src from the others.
+	Negative bool
+
+	// Pattern is set if Src contains a "*".
+	Pattern bool
+
+	// Matching is set for the ":" push refspec, which pushes every branch
+	// that exists on both sides.
+	Matching bool
+
+	// ExactSHA1 is set for a fetch refspec whose Src is an object name.
+	ExactSHA1 bool
+}
+
+// A RefSpecs is an ordered list of refspecs, such as the fetch refspecs
+// of a remote.
+type RefSpecs []*RefSpec
+
+// ParseFetchRefSpec parses a refspec of remote.<name>.fetch or "git fetch".
+func ParseFetchRefSpec(spec string) (*RefSpec, error) {
+	return parseRefSpec(spec, true)
+}
+
+// ParsePushRefSpec parses a refspec of remote.<name>.push or "git push".
+func ParsePushRefSpec(spec string) (*RefSpec, error) {
+	return parseRefSpec(spec, false)
+}
+
+// parseRefSpec follows git's parse_refspec.
+func parseRefSpec(spec string, fetch bool) (*RefSpec, error) {
+	invalid := fmt.Errorf("invalid refspec '%s'", spec)
+
+	rs := &RefSpec{}
+	lhs := spec
+	switch {
+	case strings.HasPrefix(lhs, "+"):
+		rs.Force, lhs = true, lhs[1:]
+	case strings.HasPrefix(lhs, "^"):
+		rs.Negative, lhs = true, lhs[1:]
+	}
+
+	colon := strings.LastIndexByte(lhs, ':')
+	hasDst := colon >= 0
+
+	// negative refspecs only have one side
+	if rs.Negative && hasDst {
+		return nil, invalid
+	}
+
+	// ":" and "+:" push matching refs
+	if !fetch && lhs == ":" {
+		rs.Matching = true
+		return rs, nil
+	}
+
+	var dstGlob bool
+	if hasDst {
+		lhs, rs.Dst = lhs[:colon], lhs[colon+1:]
+		dstGlob = strings.Contains(rs.Dst, "*")
+	}
+
+	if strings.Contains(lhs, "*") {
+		if (hasDst && !dstGlob) || (!hasDst && !rs.Negative && fetch) {
+			return nil, invalid
+		}
+		rs.Pattern = true
+	} else if dstGlob {
+		return nil, invalid
+	}
+
+	rs.Src = lhs
+	if lhs == "@" {
+		rs.Src = "HEAD"
+	}
+
+	valid := func(name string) bool {
+		return checkRefName(name, rs.Pattern)
+	}
+	isObjectName := func(name string) bool {
+		_, err := hex.DecodeString(name)
+		return (len(name) == 40 || len(name) == 64) && err == nil
+	}
+
+	switch {
+	case rs.Negative:
+		// a ref or pattern of refs to exclude, but not an object name
+		if rs.Src == "" || isObjectName(rs.Src) || !valid(rs.Src) {
+			return nil, invalid
+		}
+	case fetch:
+		switch {
+		case rs.Src == "":
+			// empty means HEAD
+		case isObjectName(rs.Src):
+			rs.ExactSHA1 = true
+		case !valid(rs.Src):
+			return nil, invalid
+		}
+
+		// an empty destination means do not store
+		if rs.Dst != "" && !valid(rs.Dst) {
+			return nil, invalid
+		}
+	default:
+		// an empty source deletes, and anything else may be a
+		// revision, so only patterns are checked.
+		if rs.Src != "" && rs.Pattern && !valid(rs.Src) {
+			return nil, invalid
+		}
+
+		switch {
+		case !hasDst:
+			if !valid(rs.Src) {
+				return nil, invalid
+			}
+		case rs.Dst == "":
+			return nil, invalid
+		case !valid(rs.Dst):
+			return nil, invalid
+		}
+	}
+	return rs, nil
+}
+
+// String returns the refspec in the form it was parsed from.
+func (rs *RefSpec) String() string {
+	var b strings.Builder
+	switch {
+	case rs.Force:
+		b.WriteByte('+')
+	case rs.Negative:
+		b.WriteByte('^')
+	}
+	if rs.Matching {
+		b.WriteByte(':')
+		return b.String()
+	}
+
+	b.WriteString(rs.Src)
+	if rs.Dst != "" {
+		b.WriteString(":" + rs.Dst)
+	}
+	return b.String()
+}
+
+// Match reports whether the ref name matches the source of rs.
+func (rs *RefSpec) Match(name string) bool {
+	if rs.Pattern {
+		_, ok := matchRefPattern(rs.Src, name, "")
+		return ok
+	}
+	return rs.Src == name
+}
+
+// Dest maps the source ref name to its destination.
+func (rs *RefSpec) Dest(name string) (string, bool) {
+	if rs.Negative || rs.Matching {
+		return "", false
+	}
+	if rs.Pattern {
+		return matchRefPattern(rs.Src, name, rs.Dst)
+	}
+	if rs.Src != name {
+		return "", false
+	}
+	return rs.Dst, true
+}
+
+// Source maps the destination ref name back to its source.
+func (rs *RefSpec) Source(name string) (string, bool) {
+	if rs.Negative || rs.Matching || rs.Dst == "" {
+		return "", false
+	}
+	if rs.Pattern {
+		return matchRefPattern(rs.Dst, name, rs.Src)
+	}
+	if rs.Dst != name {
+		return "", false
+	}
+	return rs.Src, true
+}
+
+// Excluded reports whether a negative refspec in rss matches name.
+func (rss RefSpecs) Excluded(name string) bool {
+	for _, rs := range rss {
+		if rs.Negative && rs.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dest maps the source ref name to the destination of the first refspec
+// that matches it, unless a negative refspec excludes it.
+func (rss RefSpecs) Dest(name string) (string, bool) {
+	if rss.Excluded(name) {
+		return "", false
+	}
+	for _, rs := range rss {
+		if dst, ok := rs.Dest(name); ok {
+			return dst, true
+		}
+	}
+	return "", false
+}
+
+// Source maps the destination ref name back to the source of the first
+// refspec that matches it, unless a negative refspec excludes that
+// source. This is how a remote-tracking ref is traced to the remote ref
+// it tracks.
+func (rss RefSpecs) Source(name string) (string, bool) {
+	for _, rs := range rss {
+		if src, ok := rs.Source(name); ok {
+			if rss.Excluded(src) {
+				return "", false
+			}
+			return src, true
+		}
+	}
+	return "", false
+}
+
+// matchRefPattern matches name against pattern, which contains one "*".
+// If it matches, the part of name matched by the "*" replaces the "*" in
+// value.
+func matchRefPattern(pattern, name, value string) (string, bool) {
+	prefix, suffix, _ := strings.Cut(pattern, "*")
+	if len(name) < len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+
+	star := name[len(prefix) : len(name)-len(suffix)]
+	before, after, _ := strings.Cut(value, "*")
+	return before + star + after, true
+}
+
+// checkRefName reports whether name is a valid ref name, following git's
+// check_refname_format with one level names allowed. If pattern is set,
+// name may contain a single "*".
+func checkRefName(name string, pattern bool) bool {
+	if name == "" || name == "@" || strings.HasSuffix(name, ".") {
+		return false
+	}
+
+	for _, component := range strings.Split(name, "/") {
+		if component == "" || component[0] == '.' || strings.HasSuffix(component, ".lock") {
+			return false
+		}
+
+		var last byte
+		for i := 0; i < len(component); i++ {
+			switch c := component[i]; {
+			case c < 0x20 || c == 0x7f:
+				return false
+			case strings.IndexByte(" ~^:?[\\", c) >= 0:
+				return false
+			case c == '*':
+				if !pattern {
+					return false
+				}
+				pattern = false
+			case c == '.' && last == '.':
+				return false
+			case c == '{' && last == '@':
+				return false
+			}
+			last = component[i]
+		}
+	}
+	return true
+}
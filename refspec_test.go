@@ -0,0 +1,115 @@
+package gitconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRefSpec(t *testing.T) {
+	tests := []struct {
+		spec  string
+		fetch bool
+		want  *RefSpec
+	}{
+		{"+refs/heads/*:refs/remotes/origin/*", true, &RefSpec{Src: "refs/heads/*", Dst: "refs/remotes/origin/*", Force: true, Pattern: true}},
+		{"refs/heads/main:refs/remotes/origin/main", true, &RefSpec{Src: "refs/heads/main", Dst: "refs/remotes/origin/main"}},
+		{"main", true, &RefSpec{Src: "main"}},
+		{"", true, &RefSpec{}},
+		{"refs/heads/main:", true, &RefSpec{Src: "refs/heads/main"}},
+		{"^refs/heads/tmp*", true, &RefSpec{Src: "refs/heads/tmp*", Negative: true, Pattern: true}},
+		{"0123456789abcdef0123456789abcdef01234567:refs/tmp", true, &RefSpec{Src: "0123456789abcdef0123456789abcdef01234567", Dst: "refs/tmp", ExactSHA1: true}},
+		{"@:refs/heads/main", true, &RefSpec{Src: "HEAD", Dst: "refs/heads/main"}},
+		{":", false, &RefSpec{Matching: true}},
+		{"+:", false, &RefSpec{Matching: true, Force: true}},
+		{":refs/heads/gone", false, &RefSpec{Dst: "refs/heads/gone"}},
+		{"HEAD~1:refs/heads/main", false, &RefSpec{Src: "HEAD~1", Dst: "refs/heads/main"}},
+		{"refs/heads/*", false, &RefSpec{Src: "refs/heads/*", Pattern: true}},
+
+		{"refs/heads/*", true, nil},
+		{"refs/heads/*:refs/remotes/origin/main", true, nil},
+		{"refs/heads/main:refs/remotes/*", true, nil},
+		{"^refs/heads/tmp:refs/tmp", true, nil},
+		{"^", true, nil},
+		{"^0123456789abcdef0123456789abcdef01234567", true, nil},
+		{"refs/heads/a..b", true, nil},
+		{"refs/heads/a b", true, nil},
+		{"refs/heads/.hidden", true, nil},
+		{"refs/heads/main.lock", true, nil},
+		{"refs/heads/@{1}", true, nil},
+		{"refs/heads//main", true, nil},
+		{"refs/heads/main.", true, nil},
+		{"refs/*/*:refs/*/*", true, nil},
+		{"main:", false, nil},
+		{"HEAD~1", false, nil},
+	}
+
+	for _, test := range tests {
+		parse := ParsePushRefSpec
+		if test.fetch {
+			parse = ParseFetchRefSpec
+		}
+
+		got, err := parse(test.spec)
+		if test.want == nil {
+			if err == nil || err.Error() != "invalid refspec '"+test.spec+"'" {
+				t.Errorf("%q: want invalid refspec error, got %+v, %v", test.spec, got, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("%q: want %+v, got %+v", test.spec, test.want, got)
+		}
+		// "@" and a trailing ":" do not round trip
+		if s := got.String(); s != test.spec && !strings.HasPrefix(test.spec, "@") && !strings.HasSuffix(test.spec, ":") {
+			t.Errorf("%q: want String() to round trip, got %q", test.spec, s)
+		}
+	}
+}
+
+func TestRefSpecsMapping(t *testing.T) {
+	var rss RefSpecs
+	for _, spec := range []string{
+		"+refs/heads/*:refs/remotes/origin/*",
+		"refs/tags/v1:refs/tags/stable",
+		"^refs/heads/tmp/*",
+		"refs/pull/*/head:refs/remotes/pr/*",
+	} {
+		rs, err := ParseFetchRefSpec(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rss = append(rss, rs)
+	}
+
+	dests := map[string]string{
+		"refs/heads/main":        "refs/remotes/origin/main",
+		"refs/heads/feature/x":   "refs/remotes/origin/feature/x",
+		"refs/tags/v1":           "refs/tags/stable",
+		"refs/pull/12/head":      "refs/remotes/pr/12",
+		"refs/heads/tmp/scratch": "",
+		"refs/tags/v2":           "",
+	}
+	for src, want := range dests {
+		if got, ok := rss.Dest(src); got != want || ok != (want != "") {
+			t.Errorf("want %s mapped to %q, got %q, %t", src, want, got, ok)
+		}
+	}
+
+	sources := map[string]string{
+		"refs/remotes/origin/main":    "refs/heads/main",
+		"refs/tags/stable":            "refs/tags/v1",
+		"refs/remotes/pr/12":          "refs/pull/12/head",
+		"refs/remotes/origin/tmp/foo": "",
+		"refs/remotes/upstream/main":  "",
+	}
+	for dst, want := range sources {
+		if got, ok := rss.Source(dst); got != want || ok != (want != "") {
+			t.Errorf("want %s traced to %q, got %q, %t", dst, want, got, ok)
+		}
+	}
+}
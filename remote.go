@@ -0,0 +1,175 @@
+package gitconfig
+
+// A Remote is the configuration of a remote repository, read from its
+// [remote "<name>"] sections.
+type Remote struct {
+	Name string
+
+	// URLs and PushURLs are the url and pushurl values in order,
+	// rewritten by url.<base>.insteadOf.
+	URLs     []string
+	PushURLs []string
+
+	// Fetch and Push are the fetch and push refspecs in order.
+	Fetch []string
+	Push  []string
+
+	// TagOpt is "--tags" or "--no-tags", or empty if unset.
+	TagOpt string
+
+	// Prune is nil if unset, in which case fetch.prune applies.
+	Prune *bool
+
+	Mirror bool
+	Proxy  string
+
+	// pushAliases are the URLs rewritten by url.<base>.pushInsteadOf, if
+	// there are no PushURLs.
+	pushAliases []string
+}
+
+// FetchURL returns the URL fetched from, the first of URLs, or the empty
+// string if there is none.
+func (r *Remote) FetchURL() string {
+	if len(r.URLs) == 0 {
+		return ""
+	}
+	return r.URLs[0]
+}
+
+// ParseFetchURL parses FetchURL. It returns nil if the remote has no URL.
+func (r *Remote) ParseFetchURL() (*URL, error) {
+	if len(r.URLs) == 0 {
+		return nil, nil
+	}
+	return ParseURL(r.URLs[0])
+}
+
+// PushTargets returns the URLs pushed to: PushURLs if any are set.
+// Otherwise, as in git, the URLs that url.<base>.pushInsteadOf rewrites
+// are pushed to in their rewritten form, and if it rewrites none of them,
+// URLs are.
+func (r *Remote) PushTargets() []string {
+	if len(r.PushURLs) > 0 {
+		return r.PushURLs
+	}
+	if len(r.pushAliases) > 0 {
+		return r.pushAliases
+	}
+	return r.URLs
+}
+
+// FetchRefSpecs parses the Fetch refspecs.
+func (r *Remote) FetchRefSpecs() (RefSpecs, error) {
+	return parseRefSpecs(r.Fetch, ParseFetchRefSpec)
+}
+
+// PushRefSpecs parses the Push refspecs.
+func (r *Remote) PushRefSpecs() (RefSpecs, error) {
+	return parseRefSpecs(r.Push, ParsePushRefSpec)
+}
+
+func parseRefSpecs(specs []string, parse func(string) (*RefSpec, error)) (RefSpecs, error) {
+	rss := make(RefSpecs, 0, len(specs))
+	for _, spec := range specs {
+		rs, err := parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		rss = append(rss, rs)
+	}
+	return rss, nil
+}
+
+// Remotes returns every configured remote, in the order they first
+// appear.
+func (c *Config) Remotes() ([]*Remote, error) {
+	var (
+		remotes []*Remote
+		byName  = make(map[string]*Remote)
+	)
+	for _, s := range c.Sections() {
+		if s.Type != "remote" || s.ID == "" {
+			continue
+		}
+
+		r, ok := byName[s.ID]
+		if !ok {
+			r = &Remote{Name: s.ID}
+			byName[s.ID] = r
+			remotes = append(remotes, r)
+		}
+		if err := r.set(s); err != nil {
+			return nil, err
+		}
+	}
+
+	rewrites, pushRewrites := c.urlRewrites("insteadof"), c.urlRewrites("pushinsteadof")
+	for _, r := range remotes {
+		for i, url := range r.URLs {
+			if len(r.PushURLs) == 0 {
+				if pushURL := rewriteURL(pushRewrites, url); pushURL != url {
+					r.pushAliases = append(r.pushAliases, pushURL)
+				}
+			}
+			r.URLs[i] = rewriteURL(rewrites, url)
+		}
+		for i, url := range r.PushURLs {
+			r.PushURLs[i] = rewriteURL(rewrites, url)
+		}
+	}
+	return remotes, nil
+}
+
+// Remote returns the remote called name, or nil if it is not configured.
+func (c *Config) Remote(name string) (*Remote, error) {
+	remotes, err := c.Remotes()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range remotes {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+// set applies the values of a [remote] section to r. A key appears at most
+// once in a section, the values of a repeated key continuing in the
+// sections that follow, so the order of the keys does not matter.
+func (r *Remote) set(s *Section) error {
+	key := func(name string) string {
+		return "remote." + r.Name + "." + name
+	}
+
+	for name, value := range s.Values {
+		switch name {
+		case "url":
+			r.URLs = append(r.URLs, value)
+		case "pushurl":
+			r.PushURLs = append(r.PushURLs, value)
+		case "fetch":
+			r.Fetch = append(r.Fetch, value)
+		case "push":
+			r.Push = append(r.Push, value)
+		case "tagopt":
+			r.TagOpt = value
+		case "proxy":
+			r.Proxy = value
+		case "prune":
+			prune, err := parseBool(key(name), value)
+			if err != nil {
+				return err
+			}
+			r.Prune = &prune
+		case "mirror":
+			mirror, err := parseBool(key(name), value)
+			if err != nil {
+				return err
+			}
+			r.Mirror = mirror
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package gitconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemotes(t *testing.T) {
+	sections, err := Parse([]byte(`[remote "origin"]
+	url = https://github.com/benburkert/go-gitconfig
+	fetch = +refs/heads/*:refs/remotes/origin/*
+	url = gh:benburkert/mirror
+	fetch = +refs/tags/*:refs/tags/*
+	prune = true
+	tagOpt = --no-tags
+[remote "backup"]
+	url = /srv/backup.git
+	pushurl = gh:benburkert/backup
+	push = refs/heads/main
+	mirror = yes
+	proxy = http://proxy.example.com
+[url "git@github.com:"]
+	insteadOf = gh:
+[url "ssh://git@github.com/"]
+	insteadOf = https://github.com/
+[remote "origin"]
+	push = refs/heads/*
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{Layers: []*Layer{{Scope: ScopeLocal, Sections: sections}}}
+
+	remotes, err := conf.Remotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prune := true
+	want := []*Remote{
+		{
+			Name:   "origin",
+			URLs:   []string{"ssh://git@github.com/benburkert/go-gitconfig", "git@github.com:benburkert/mirror"},
+			Fetch:  []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"},
+			Push:   []string{"refs/heads/*"},
+			TagOpt: "--no-tags",
+			Prune:  &prune,
+		},
+		{
+			Name:     "backup",
+			URLs:     []string{"/srv/backup.git"},
+			PushURLs: []string{"git@github.com:benburkert/backup"},
+			Push:     []string{"refs/heads/main"},
+			Mirror:   true,
+			Proxy:    "http://proxy.example.com",
+		},
+	}
+	if !reflect.DeepEqual(want, remotes) {
+		t.Errorf("want remotes %+v, got %+v", want, remotes)
+	}
+
+	if got, want := remotes[0].FetchURL(), "ssh://git@github.com/benburkert/go-gitconfig"; got != want {
+		t.Errorf("want origin fetch URL %q, got %q", want, got)
+	}
+	if got, want := remotes[0].PushTargets(), remotes[0].URLs; !reflect.DeepEqual(want, got) {
+		t.Errorf("want origin push URLs %q, got %q", want, got)
+	}
+	if got, want := remotes[1].PushTargets(), []string{"git@github.com:benburkert/backup"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want backup push URLs %q, got %q", want, got)
+	}
+
+	if r, err := conf.Remote("missing"); r != nil || err != nil {
+		t.Errorf("want no remote, got %+v, %v", r, err)
+	}
+}
+
+func TestRewriteURLLongestPrefix(t *testing.T) {
+	sections, err := Parse([]byte(`[url "short:"]
+	insteadOf = https://example.com/
+[url "long:"]
+	insteadOf = https://example.com/org/
+[url "first:"]
+	insteadOf = git://a/
+[url "second:"]
+	insteadOf = git://a/
+[url "short:"]
+	insteadOf = http://example.com/
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewrites := (&Config{Layers: []*Layer{{Sections: sections}}}).urlRewrites("insteadof")
+
+	tests := map[string]string{
+		"https://example.com/repo":     "short:repo",
+		"https://example.com/org/repo": "long:repo",
+		"http://example.com/repo":      "short:repo",
+		"git://a/repo":                 "first:repo",
+		"ssh://example.com/repo":       "ssh://example.com/repo",
+	}
+	for url, want := range tests {
+		if got := rewriteURL(rewrites, url); got != want {
+			t.Errorf("want %s rewritten to %q, got %q", url, want, got)
+		}
+	}
+}
+
+func TestRewriteURL(t *testing.T) {
+	sections, err := Parse([]byte(`[url "git@github.com:"]
+	insteadOf = https://github.com/
+	insteadOf = gh:
+	pushInsteadOf = https://github.com/
+[url "ssh://git@push.example.com/"]
+	pushInsteadOf = https://example.com/
+[remote "origin"]
+	url = https://github.com/benburkert/go-gitconfig
+[remote "mixed"]
+	url = https://example.com/repo
+	url = https://other.example.com/repo
+[remote "explicit"]
+	url = https://example.com/repo
+	pushurl = gh:benburkert/push
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{Layers: []*Layer{{Sections: sections}}}
+
+	tests := []struct {
+		url  string
+		dir  Direction
+		want string
+	}{
+		{"https://github.com/a/b", DirectionFetch, "git@github.com:a/b"},
+		{"gh:a/b", DirectionFetch, "git@github.com:a/b"},
+		{"https://github.com/a/b", DirectionPush, "git@github.com:a/b"},
+		{"gh:a/b", DirectionPush, "git@github.com:a/b"},
+		{"https://example.com/a", DirectionFetch, "https://example.com/a"},
+		{"https://example.com/a", DirectionPush, "ssh://git@push.example.com/a"},
+	}
+	for _, test := range tests {
+		if got := conf.RewriteURL(test.url, test.dir); got != test.want {
+			t.Errorf("want %s rewritten to %q in direction %d, got %q", test.url, test.want, test.dir, got)
+		}
+	}
+
+	pushTargets := map[string][]string{
+		"origin":   {"git@github.com:benburkert/go-gitconfig"},
+		"mixed":    {"ssh://git@push.example.com/repo"},
+		"explicit": {"git@github.com:benburkert/push"},
+	}
+	for name, want := range pushTargets {
+		r, err := conf.Remote(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.PushTargets(); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %s push URLs %q, got %q", name, want, got)
+		}
+	}
+}
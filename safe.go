@@ -0,0 +1,143 @@
+package gitconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A DubiousOwnershipError is returned by Discover for a repository that is
+// owned by another user and not listed in safe.directory.
+type DubiousOwnershipError struct {
+	Path string
+}
+
+func (e *DubiousOwnershipError) Error() string {
+	return fmt.Sprintf("detected dubious ownership in repository at '%s'\n"+
+		"To add an exception for this directory, call:\n\n"+
+		"\tgit config --global --add safe.directory %s", e.Path, e.Path)
+}
+
+// checkRepository applies git's checks to a repository discovered at
+// workTree, or found as a bare git directory if workTree is empty: a bare
+// repository must be allowed by safe.bareRepository, and a repository
+// owned by another user must be allowed by safe.directory. gitFile is the
+// .git file that pointed to the repository, if any. Both settings are
+// read from the protected scopes only.
+func (l *Loader) checkRepository(repo *Repository, workTree, gitFile string) error {
+	c := *l
+	c.GitDir, c.CommonDir = "", ""
+	conf, err := c.Load()
+	if err != nil {
+		return err
+	}
+	protected := conf.Protected()
+
+	if workTree == "" {
+		allowed, _ := protected.Get("safe.barerepository")
+		switch allowed {
+		case "", "all":
+		case "explicit":
+			if !isImplicitBareRepository(repo.GitDir) {
+				return fmt.Errorf("cannot use bare repository '%s' (safe.bareRepository is '%s')", repo.GitDir, allowed)
+			}
+		default:
+			return fmt.Errorf("invalid value for 'safe.bareRepository': '%s'", allowed)
+		}
+	}
+
+	if l.ownedByCurrentUser(gitFile, workTree, repo.GitDir) {
+		return nil
+	}
+
+	path := workTree
+	if path == "" {
+		path = repo.GitDir
+	}
+	if real, err := l.fileSystem().EvalSymlinks(path); err == nil {
+		path = real
+	}
+	if !l.isSafeDirectory(protected, path) {
+		return &DubiousOwnershipError{Path: path}
+	}
+	return nil
+}
+
+// isImplicitBareRepository reports whether the bare repository at dir is
+// the git directory of a working tree, a linked worktree or a submodule,
+// which are used even if safe.bareRepository is "explicit".
+func isImplicitBareRepository(dir string) bool {
+	dir = filepath.ToSlash(dir)
+	return strings.HasSuffix(dir, "/.git") ||
+		strings.Contains(dir, "/.git/worktrees/") ||
+		strings.Contains(dir, "/.git/modules/")
+}
+
+// ownedByCurrentUser reports whether every non-empty path is owned by the
+// current user. When running as root through sudo, SUDO_UID is the
+// current user. Owners are not checked where the file system does not
+// report them.
+func (l *Loader) ownedByCurrentUser(paths ...string) bool {
+	if ok, _ := l.envBool("GIT_TEST_ASSUME_DIFFERENT_OWNER"); ok {
+		return false
+	}
+
+	euid := os.Geteuid()
+	if euid == 0 {
+		if sudo, err := strconv.Atoi(l.getenv("SUDO_UID")); err == nil {
+			euid = sudo
+		}
+	}
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		fi, err := l.fileSystem().Stat(path)
+		if err != nil {
+			return false
+		}
+		if uid, _, ok := fileOwner(fi); ok && uid != euid {
+			return false
+		}
+	}
+	return true
+}
+
+// isSafeDirectory reports whether the safe.directory values of conf allow
+// the repository at path. A value of "*" allows every repository, a value
+// ending in "/*" allows every repository below it, and an empty value
+// resets the list.
+func (l *Loader) isSafeDirectory(conf *Config, path string) bool {
+	safe := false
+	for _, value := range conf.GetAll("safe.directory") {
+		switch {
+		case value == "":
+			safe = false
+		case value == "*":
+			safe = true
+		default:
+			allowed, err := l.expandPath(value)
+			if err != nil {
+				continue
+			}
+
+			if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+				if strings.HasPrefix(path, prefix+"/") {
+					safe = true
+				}
+				continue
+			}
+
+			allowed = filepath.Clean(allowed)
+			if real, err := l.fileSystem().EvalSymlinks(allowed); err == nil && real == path {
+				safe = true
+			} else if allowed == path {
+				safe = true
+			}
+		}
+	}
+	return safe
+}
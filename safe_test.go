@@ -0,0 +1,97 @@
+package gitconfig
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverSafeDirectory(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeGitDir(t, filepath.Join(dir, "src", "repo", ".git"))
+	writeGitDir(t, filepath.Join(dir, "bare.git"))
+	writeFiles(t, dir, map[string]string{
+		// a repository cannot mark itself as safe.
+		"src/repo/.git/config": "[safe]\n\tdirectory = *\n",
+	})
+	repo := filepath.Join(dir, "src", "repo")
+
+	tests := []struct {
+		global string
+		safe   bool
+	}{
+		{"", false},
+		{"[safe]\n\tdirectory = " + repo + "\n", true},
+		{"[safe]\n\tdirectory = " + filepath.Join(dir, "src") + "/*\n", true},
+		{"[safe]\n\tdirectory = " + filepath.Join(dir, "sr") + "/*\n", false},
+		{"[safe]\n\tdirectory = *\n", true},
+		{"[safe]\n\tdirectory = ~/src/repo\n", true},
+		{"[safe]\n\tdirectory = *\n\tdirectory =\n", false},
+		{"[safe]\n\tdirectory = " + filepath.Join(dir, "src") + "\n", false},
+	}
+
+	for _, test := range tests {
+		writeFiles(t, dir, map[string]string{"global": test.global})
+		l := &Loader{Env: testEnv(map[string]string{
+			"HOME":                            dir,
+			"GIT_CONFIG_NOSYSTEM":             "1",
+			"GIT_CONFIG_GLOBAL":               filepath.Join(dir, "global"),
+			"GIT_TEST_ASSUME_DIFFERENT_OWNER": "1",
+		})}
+
+		_, err := l.Discover(repo)
+		var ownership *DubiousOwnershipError
+		switch {
+		case test.safe && err != nil:
+			t.Errorf("%q: want safe repository, got %v", test.global, err)
+		case !test.safe && !errors.As(err, &ownership):
+			t.Errorf("%q: want dubious ownership error, got %v", test.global, err)
+		case !test.safe && ownership.Path != repo:
+			t.Errorf("%q: want dubious ownership of %s, got %s", test.global, repo, ownership.Path)
+		}
+	}
+
+	// the current user owns the repository.
+	l := &Loader{Env: testEnv(map[string]string{"GIT_CONFIG_NOSYSTEM": "1"})}
+	if _, err := l.Discover(repo); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDiscoverSafeBareRepository(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeGitDir(t, filepath.Join(dir, "bare.git"))
+	writeGitDir(t, filepath.Join(dir, "repo", ".git"))
+
+	env := map[string]string{
+		"GIT_CONFIG_NOSYSTEM":     "1",
+		"GIT_CONFIG_COUNT":        "1",
+		"GIT_CONFIG_KEY_0":        "safe.bareRepository",
+		"GIT_CONFIG_VALUE_0":      "explicit",
+		"GIT_CEILING_DIRECTORIES": dir,
+	}
+
+	l := &Loader{Env: testEnv(env)}
+	want := "cannot use bare repository '" + filepath.Join(dir, "bare.git") + "' (safe.bareRepository is 'explicit')"
+	if _, err := l.Discover(filepath.Join(dir, "bare.git")); err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+
+	// the git directory of a working tree is not an explicit bare
+	// repository.
+	if _, err := l.Discover(filepath.Join(dir, "repo", ".git")); err != nil {
+		t.Error(err)
+	}
+
+	// GIT_DIR names the repository explicitly.
+	env["GIT_DIR"] = filepath.Join(dir, "bare.git")
+	if _, err := l.Discover(dir); err != nil {
+		t.Error(err)
+	}
+}
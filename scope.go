@@ -0,0 +1,239 @@
+package gitconfig
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A Scope identifies where a layer of configuration comes from.
+type Scope int
+
+const (
+	ScopeUnknown Scope = iota
+	ScopeSystem
+	ScopeGlobal
+	ScopeLocal
+	ScopeWorktree
+	ScopeCommand
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeSystem:
+		return "system"
+	case ScopeGlobal:
+		return "global"
+	case ScopeLocal:
+		return "local"
+	case ScopeWorktree:
+		return "worktree"
+	case ScopeCommand:
+		return "command"
+	default:
+		return "unknown"
+	}
+}
+
+// A Layer is the configuration read from a single source.
+type Layer struct {
+	Scope    Scope
+	Path     string // empty if the layer was not read from a file
+	Sections []*Section
+}
+
+// A Config is a stack of layers, ordered from lowest to highest
+// precedence.
+type Config struct {
+	Layers []*Layer
+}
+
+// Sections returns the sections of every layer in order.
+func (c *Config) Sections() []*Section {
+	var sections []*Section
+	for _, layer := range c.Layers {
+		sections = append(sections, layer.Sections...)
+	}
+	return sections
+}
+
+// Protected returns the layers of the system, global and command scopes,
+// which a repository cannot change. Git reads settings that control
+// whether a repository is trusted, such as safe.directory, only from them.
+func (c *Config) Protected() *Config {
+	protected := &Config{}
+	for _, layer := range c.Layers {
+		switch layer.Scope {
+		case ScopeSystem, ScopeGlobal, ScopeCommand:
+			protected.Layers = append(protected.Layers, layer)
+		}
+	}
+	return protected
+}
+
+// Get returns the value of key, such as "user.name" or
+// "remote.origin.url". If key is set more than once, the last value wins.
+func (c *Config) Get(key string) (string, bool) {
+	values := c.GetAll(key)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// GetAll returns every value of a multi-valued key in order.
+func (c *Config) GetAll(key string) []string {
+	stype, id, name, ok := splitKey(key)
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, layer := range c.Layers {
+		for _, s := range layer.Sections {
+			if s.Type != stype || s.ID != id {
+				continue
+			}
+			if value, ok := s.Values[name]; ok {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// Bool returns the boolean value of key, or def if it is not set.
+func (c *Config) Bool(key string, def bool) (bool, error) {
+	value, ok := c.Get(key)
+	if !ok {
+		return def, nil
+	}
+	return parseBool(key, value)
+}
+
+// Int returns the integer value of key, or def if it is not set. As in
+// git, the value may have a "k", "m" or "g" suffix.
+func (c *Config) Int(key string, def int) (int, error) {
+	value, ok := c.Get(key)
+	if !ok {
+		return def, nil
+	}
+	return parseInt(key, value)
+}
+
+// ParseBool parses a boolean the way git does: "true", "yes" and "on"
+// are true, "false", "no", "off" and the empty string are false, and an
+// integer is true if it is not zero.
+func ParseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off", "":
+		return false, nil
+	}
+
+	n, err := strconv.ParseInt(value, 0, 64)
+	if err != nil {
+		return false, err
+	}
+	return n != 0, nil
+}
+
+// parseBool is ParseBool with git's error message for key.
+func parseBool(key, value string) (bool, error) {
+	b, err := ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("bad boolean config value '%s' for '%s'", value, key)
+	}
+	return b, nil
+}
+
+// parseInt parses an integer with an optional "k", "m" or "g" unit, with
+// git's error messages for key.
+func parseInt(key, value string) (int, error) {
+	number, factor := value, int64(1)
+	if value != "" {
+		switch value[len(value)-1] {
+		case 'k', 'K':
+			factor = 1 << 10
+		case 'm', 'M':
+			factor = 1 << 20
+		case 'g', 'G':
+			factor = 1 << 30
+		}
+		if factor != 1 {
+			number = value[:len(value)-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(number, 0, 32)
+	if err != nil {
+		if nerr, ok := err.(*strconv.NumError); ok && nerr.Err == strconv.ErrRange {
+			return 0, fmt.Errorf("bad numeric config value '%s' for '%s': out of range", value, key)
+		}
+		return 0, fmt.Errorf("bad numeric config value '%s' for '%s': invalid unit", value, key)
+	}
+	if n*factor < math.MinInt32 || n*factor > math.MaxInt32 {
+		return 0, fmt.Errorf("bad numeric config value '%s' for '%s': out of range", value, key)
+	}
+	return int(n * factor), nil
+}
+
+// splitKey splits a "section.subsection.name" key into its parts. The
+// section and name are lowercased, while the subsection is kept as is.
+func splitKey(key string) (section, subsection, name string, ok bool) {
+	first, last := strings.IndexByte(key, '.'), strings.LastIndexByte(key, '.')
+	if first <= 0 || last == len(key)-1 {
+		return "", "", "", false
+	}
+
+	section, name = strings.ToLower(key[:first]), strings.ToLower(key[last+1:])
+	if first != last {
+		subsection = key[first+1 : last]
+	}
+	return section, subsection, name, true
+}
+
+// parseKey validates a "section.subsection.name" key the way git does for
+// keys given on the command line or in the environment, and returns its
+// parts with the section and name lowercased.
+func parseKey(key string) (section, subsection, name string, err error) {
+	last := strings.LastIndexByte(key, '.')
+	if last <= 0 {
+		return "", "", "", fmt.Errorf("key does not contain a section: %s", key)
+	}
+	if last == len(key)-1 {
+		return "", "", "", fmt.Errorf("key does not contain variable name: %s", key)
+	}
+
+	first := strings.IndexByte(key, '.')
+	if first == 0 {
+		return "", "", "", fmt.Errorf("key does not contain a section: %s", key)
+	}
+	section, name = key[:first], key[last+1:]
+	if first != last {
+		subsection = key[first+1 : last]
+	}
+
+	if !isKeyName(section) || !isKeyName(name) || !isAlpha(name[0]) {
+		return "", "", "", fmt.Errorf("invalid key: %s", key)
+	}
+	if strings.ContainsRune(subsection, '\n') {
+		return "", "", "", fmt.Errorf("invalid key (newline): %s", key)
+	}
+	return strings.ToLower(section), subsection, strings.ToLower(name), nil
+}
+
+func isKeyName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isAlpha(c) && !('0' <= c && c <= '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlpha(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,157 @@
+package gitconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// signingPrograms are the default programs of each gpg.format.
+var signingPrograms = map[string]string{
+	"openpgp": "gpg",
+	"x509":    "gpgsm",
+	"ssh":     "ssh-keygen",
+}
+
+// Signing is how git signs commits and tags.
+type Signing struct {
+	// Format is the gpg.format: "openpgp", "x509" or "ssh".
+	Format string
+
+	// Program is the gpg.<format>.program that signs, or the default of
+	// the format: "gpg", "gpgsm" or "ssh-keygen", with "~" expanded.
+	// gpg.program is an older name of gpg.openpgp.program.
+	Program string
+
+	// Key is user.signingKey: an openpgp or x509 key id, or for ssh the
+	// path of a key file with "~" expanded, or a literal public key that
+	// starts with "key::" or "ssh-". If it is not set, it is the
+	// committer identity for openpgp and x509, which gpg looks the key
+	// up by, and empty for ssh.
+	Key string
+
+	// DefaultKeyCommand is gpg.ssh.defaultKeyCommand, which prints the
+	// ssh key to sign with when Key is not set.
+	DefaultKeyCommand string
+
+	// SignCommits and SignTags are commit.gpgSign and tag.gpgSign.
+	SignCommits bool
+	SignTags    bool
+}
+
+// Signing resolves the signing configuration of conf the way git does
+// when it signs a commit or tag.
+func (l *Loader) Signing(conf *Config) (*Signing, error) {
+	s := &Signing{Format: "openpgp"}
+	if format, ok := conf.Get("gpg.format"); ok {
+		if _, ok := signingPrograms[format]; !ok {
+			return nil, fmt.Errorf("invalid value for 'gpg.format': '%s'", format)
+		}
+		s.Format = format
+	}
+
+	programs := map[string]string{}
+	for _, section := range conf.Sections() {
+		if section.Type != "gpg" {
+			continue
+		}
+		if program, ok := section.Values["program"]; ok {
+			switch section.ID {
+			case "", "openpgp":
+				programs["openpgp"] = program
+			case "x509", "ssh":
+				programs[section.ID] = program
+			}
+		}
+	}
+	var err error
+	if s.Program, err = l.expandPath(programs[s.Format]); err != nil {
+		return nil, err
+	}
+	if s.Program == "" {
+		s.Program = signingPrograms[s.Format]
+	}
+
+	if s.SignCommits, err = conf.Bool("commit.gpgSign", false); err != nil {
+		return nil, err
+	}
+	if s.SignTags, err = conf.Bool("tag.gpgSign", false); err != nil {
+		return nil, err
+	}
+	s.DefaultKeyCommand, _ = conf.Get("gpg.ssh.defaultKeyCommand")
+
+	key, _ := conf.Get("user.signingKey")
+	switch {
+	case key == "" && s.Format != "ssh":
+		committer, err := l.Committer(conf)
+		if err != nil {
+			return nil, err
+		}
+		s.Key = committer.String()
+	case s.Format == "ssh" && key != "":
+		if _, ok := literalSSHKey(key); !ok {
+			if key, err = l.expandPath(key); err != nil {
+				return nil, err
+			}
+		}
+		s.Key = key
+	default:
+		s.Key = key
+	}
+	return s, nil
+}
+
+// SSHKey returns the key that ssh signing uses: either the path of a key
+// file, or a literal public key, which git writes to a temporary file for
+// ssh-keygen to find the private key in the ssh agent. If Key is not set,
+// DefaultKeyCommand is run and the first line it prints must be a
+// literal key.
+func (s *Signing) SSHKey() (file, publicKey string, err error) {
+	key := s.Key
+	if key == "" && s.DefaultKeyCommand != "" {
+		if key, err = s.defaultSSHKey(); err != nil {
+			return "", "", err
+		}
+	}
+	if key == "" {
+		return "", "", errors.New("user.signingKey needs to be set for ssh signing")
+	}
+
+	if publicKey, ok := literalSSHKey(key); ok {
+		return "", publicKey, nil
+	}
+	return key, "", nil
+}
+
+// defaultSSHKey runs DefaultKeyCommand, split like an alias, and returns
+// the first line it prints.
+func (s *Signing) defaultSSHKey() (string, error) {
+	argv, err := splitCmdline(s.DefaultKeyCommand)
+	if err != nil {
+		return "", fmt.Errorf("malformed build-time gpg.ssh.defaultKeyCommand: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("gpg.ssh.defaultKeyCommand failed: %s %s", stderr.String(), stdout.String())
+	}
+
+	key, _, _ := strings.Cut(stdout.String(), "\n")
+	if _, ok := literalSSHKey(key); !ok {
+		return "", fmt.Errorf("gpg.ssh.defaultKeyCommand succeeded but returned no keys: %s %s", stderr.String(), stdout.String())
+	}
+	return key, nil
+}
+
+// literalSSHKey returns the public key of a "key::<key>" or "ssh-..."
+// signing key, and reports whether key is one rather than a path.
+func literalSSHKey(key string) (string, bool) {
+	if publicKey, ok := strings.CutPrefix(key, "key::"); ok {
+		return publicKey, true
+	}
+	return key, strings.HasPrefix(key, "ssh-")
+}
@@ -0,0 +1,119 @@
+package gitconfig
+
+import (
+	"testing"
+)
+
+func TestSigning(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   Signing
+		err    string
+	}{
+		{
+			name:   "openpgp",
+			config: "[user]\n\tsigningkey = BC8EDD7F\n[commit]\n\tgpgsign = true\n",
+			want:   Signing{Format: "openpgp", Program: "gpg", Key: "BC8EDD7F", SignCommits: true},
+		},
+		{
+			name:   "identity",
+			config: "[user]\n\tname = Ben\n\temail = ben@example.com\n[gpg]\n\tprogram = gpg2\n[tag]\n\tgpgSign = yes\n",
+			want:   Signing{Format: "openpgp", Program: "gpg2", Key: "Ben <ben@example.com>", SignTags: true},
+		},
+		{
+			name:   "program order",
+			config: "[gpg \"openpgp\"]\n\tprogram = first\n[gpg]\n\tprogram = second\n[gpg \"x509\"]\n\tprogram = other\n[user]\n\tsigningKey = K\n",
+			want:   Signing{Format: "openpgp", Program: "second", Key: "K"},
+		},
+		{
+			name:   "x509",
+			config: "[gpg]\n\tformat = x509\n[user]\n\tsigningKey = 0x1234\n",
+			want:   Signing{Format: "x509", Program: "gpgsm", Key: "0x1234"},
+		},
+		{
+			name:   "program path",
+			config: "[gpg \"x509\"]\n\tprogram = ~/bin/gpgsm\n[gpg]\n\tformat = x509\n[user]\n\tsigningKey = 0x1234\n",
+			want:   Signing{Format: "x509", Program: "/home/ben/bin/gpgsm", Key: "0x1234"},
+		},
+		{
+			name:   "ssh path",
+			config: "[gpg]\n\tformat = ssh\n[gpg \"ssh\"]\n\tprogram = /usr/local/bin/ssh-keygen\n[user]\n\tsigningKey = ~/.ssh/id_ed25519.pub\n",
+			want:   Signing{Format: "ssh", Program: "/usr/local/bin/ssh-keygen", Key: "/home/ben/.ssh/id_ed25519.pub"},
+		},
+		{
+			name:   "ssh literal",
+			config: "[gpg]\n\tformat = ssh\n[user]\n\tsigningKey = key::ssh-ed25519 AAAA ben\n",
+			want:   Signing{Format: "ssh", Program: "ssh-keygen", Key: "key::ssh-ed25519 AAAA ben"},
+		},
+		{
+			name:   "ssh default key command",
+			config: "[gpg]\n\tformat = ssh\n[gpg \"ssh\"]\n\tdefaultKeyCommand = ssh-add -L\n",
+			want:   Signing{Format: "ssh", Program: "ssh-keygen", DefaultKeyCommand: "ssh-add -L"},
+		},
+		{
+			name:   "bad format",
+			config: "[gpg]\n\tformat = pgp\n",
+			err:    "invalid value for 'gpg.format': 'pgp'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sections, err := Parse([]byte(test.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			conf := &Config{Layers: []*Layer{{Sections: sections}}}
+
+			l := &Loader{Env: testEnv(map[string]string{"HOME": "/home/ben"})}
+			s, err := l.Signing(conf)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Errorf("want error %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *s != test.want {
+				t.Errorf("want %+v, got %+v", test.want, *s)
+			}
+		})
+	}
+}
+
+func TestSSHKey(t *testing.T) {
+	tests := []struct {
+		signing   Signing
+		file      string
+		publicKey string
+		err       string
+	}{
+		{signing: Signing{Key: "/home/ben/.ssh/id_ed25519"}, file: "/home/ben/.ssh/id_ed25519"},
+		{signing: Signing{Key: "key::ecdsa-sha2-nistp256 AAAA"}, publicKey: "ecdsa-sha2-nistp256 AAAA"},
+		{signing: Signing{Key: "ssh-ed25519 AAAA"}, publicKey: "ssh-ed25519 AAAA"},
+		{signing: Signing{DefaultKeyCommand: `printf 'ssh-ed25519 AAAA\nssh-rsa BBBB\n'`}, publicKey: "ssh-ed25519 AAAA"},
+		{signing: Signing{DefaultKeyCommand: "echo nokey"}, err: "gpg.ssh.defaultKeyCommand succeeded but returned no keys:  nokey\n"},
+		{signing: Signing{DefaultKeyCommand: "false"}, err: "gpg.ssh.defaultKeyCommand failed:  "},
+		{signing: Signing{}, err: "user.signingKey needs to be set for ssh signing"},
+	}
+
+	for _, test := range tests {
+		file, publicKey, err := test.signing.SSHKey()
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%+v: want error %q, got %v", test.signing, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %v", test.signing, err)
+			continue
+		}
+		if file != test.file || publicKey != test.publicKey {
+			t.Errorf("%+v: want %q, %q, got %q, %q", test.signing, test.file, test.publicKey, file, publicKey)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package gitconfig
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// A Submodule is a submodule declared in .gitmodules.
+type Submodule struct {
+	Name string
+
+	// Path is where the submodule is checked out, relative to the top of
+	// the working tree.
+	Path string
+
+	URL    string
+	Branch string
+
+	// Update is "checkout", "rebase", "merge", "none" or "!<command>".
+	Update string
+
+	// Ignore is "all", "dirty", "untracked" or "none".
+	Ignore string
+
+	Shallow bool
+
+	// FetchRecurseSubmodules is "true", "false" or "on-demand", or empty
+	// if unset.
+	FetchRecurseSubmodules string
+}
+
+// Submodules returns the submodules declared in the .gitmodules sections,
+// in the order they first appear. The submodule.<name>.* values of conf,
+// the repository's config, override those of .gitmodules, except for
+// path. conf may be nil.
+//
+// The .gitmodules entries are checked with CheckGitmodules first.
+func Submodules(gitmodules []*Section, conf *Config) ([]*Submodule, error) {
+	if err := CheckGitmodules(gitmodules); err != nil {
+		return nil, err
+	}
+
+	var (
+		submodules []*Submodule
+		byName     = make(map[string]*Submodule)
+	)
+	for _, s := range gitmodules {
+		if s.Type != "submodule" || s.ID == "" {
+			continue
+		}
+
+		sm, ok := byName[s.ID]
+		if !ok {
+			sm = &Submodule{Name: s.ID}
+			byName[s.ID] = sm
+			submodules = append(submodules, sm)
+		}
+		if err := sm.set(s, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf == nil {
+		return submodules, nil
+	}
+	for _, s := range conf.Sections() {
+		if sm, ok := byName[s.ID]; ok && s.Type == "submodule" {
+			if err := sm.set(s, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return submodules, nil
+}
+
+// set applies the values of a [submodule] section to sm. The path is only
+// read from .gitmodules.
+func (sm *Submodule) set(s *Section, gitmodules bool) error {
+	key := func(name string) string {
+		return "submodule." + sm.Name + "." + name
+	}
+
+	for name, value := range s.Values {
+		switch name {
+		case "path":
+			if gitmodules {
+				sm.Path = value
+			}
+		case "url":
+			sm.URL = value
+		case "branch":
+			sm.Branch = value
+		case "update":
+			sm.Update = value
+		case "ignore":
+			sm.Ignore = value
+		case "shallow":
+			shallow, err := parseBool(key(name), value)
+			if err != nil {
+				return err
+			}
+			sm.Shallow = shallow
+		case "fetchrecursesubmodules":
+			if value == "on-demand" {
+				sm.FetchRecurseSubmodules = value
+				continue
+			}
+			recurse, err := ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("bad %s argument: %s", key(name), value)
+			}
+			sm.FetchRecurseSubmodules = fmt.Sprint(recurse)
+		}
+	}
+	return nil
+}
+
+// CheckGitmodules checks .gitmodules sections the way git fsck does,
+// rejecting the entries git has been hardened against: submodule names
+// with ".." path components, URLs and paths that look like command line
+// options, relative URLs that escape their host, URLs with encoded
+// newlines, and "!command" update settings. Sections without a submodule
+// name are skipped. Every problem found is joined into the returned
+// error.
+func CheckGitmodules(sections []*Section) error {
+	var errs []error
+	for _, s := range sections {
+		if s.Type != "submodule" || s.ID == "" {
+			continue
+		}
+
+		if !checkSubmoduleName(s.ID) {
+			errs = append(errs, fmt.Errorf("disallowed submodule name: %s", s.ID))
+		}
+		if value, ok := s.Values["url"]; ok && !checkSubmoduleURL(value) {
+			errs = append(errs, fmt.Errorf("disallowed submodule url: %s", value))
+		}
+		if value, ok := s.Values["path"]; ok && strings.HasPrefix(value, "-") {
+			errs = append(errs, fmt.Errorf("disallowed submodule path: %s", value))
+		}
+		if value, ok := s.Values["update"]; ok && strings.HasPrefix(value, "!") {
+			errs = append(errs, fmt.Errorf("disallowed submodule update setting: %s", value))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkSubmoduleName rejects empty names and names with a ".." path
+// component, split at "/" or "\" to protect Windows as well.
+func checkSubmoduleName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, component := range strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if component == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSubmoduleURL follows git's check_submodule_url.
+func checkSubmoduleURL(raw string) bool {
+	if strings.HasPrefix(raw, "-") {
+		return false
+	}
+
+	if isRelativeSubmoduleURL(raw) || strings.HasPrefix(raw, "git://") {
+		// a relative URL could be appended to an http URL and decoded
+		if strings.Contains(urlDecode(raw), "\n") {
+			return false
+		}
+
+		// "../" past the root would overwrite the host of the URL it is
+		// resolved against.
+		dotdots, next := 0, raw
+		for {
+			if startsWithDotDotSlash(next) {
+				dotdots, next = dotdots+1, next[3:]
+			} else if startsWithDotSlash(next) {
+				next = next[2:]
+			} else {
+				break
+			}
+		}
+		return dotdots == 0 || !(strings.HasPrefix(next, ":") || strings.HasPrefix(next, "/"))
+	}
+
+	if curl, ok := curlURL(raw); ok {
+		u, err := ParseURL(curl)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		return !strings.Contains(urlDecode(curl), "\n")
+	}
+	return true
+}
+
+// curlURL returns the URL that git hands to curl for http, https, ftp and
+// ftps URLs, which may also be given through a remote helper, as in
+// "https::https://example.com/repo".
+func curlURL(raw string) (string, bool) {
+	if helper, address, ok := remoteHelper(raw); ok {
+		raw = address
+		switch helper {
+		case "http", "https", "ftp", "ftps":
+			return raw, true
+		}
+		return "", false
+	}
+
+	for _, scheme := range []string{"http://", "https://", "ftp://", "ftps://"} {
+		if len(raw) >= len(scheme) && strings.EqualFold(raw[:len(scheme)], scheme) {
+			return raw, true
+		}
+	}
+	return "", false
+}
+
+func isRelativeSubmoduleURL(raw string) bool {
+	return startsWithDotSlash(raw) || startsWithDotDotSlash(raw)
+}
+
+// startsWithDotSlash and startsWithDotDotSlash report whether path starts
+// with "./" or "../", where "\" also separates directories as it does on
+// Windows.
+func startsWithDotSlash(path string) bool {
+	return len(path) >= 2 && path[0] == '.' && isDirSep(path[1])
+}
+
+func startsWithDotDotSlash(path string) bool {
+	return len(path) >= 3 && path[0] == '.' && path[1] == '.' && isDirSep(path[2])
+}
+
+func isDirSep(c byte) bool {
+	return c == '/' || c == '\\'
+}
+
+// ResolveSubmoduleURL resolves a submodule URL the way "git submodule
+// init" does. A URL starting with "./" or "../" is relative to the URL of
+// the default remote for branch, the short name of the checked out
+// branch, or empty if HEAD is detached. Without a remote URL the
+// superproject is its own upstream, and workTree is used instead. Other
+// URLs are returned as they are.
+func (c *Config) ResolveSubmoduleURL(url, branch, workTree string) (string, error) {
+	if !isRelativeSubmoduleURL(url) {
+		return url, nil
+	}
+
+	remoteURL, ok := c.Get("remote." + c.DefaultRemote(branch) + ".url")
+	if !ok {
+		remoteURL = workTree
+	}
+	return RelativeURL(remoteURL, url, "")
+}
+
+// DefaultRemote returns branch.<branch>.remote, or "origin" if it is not
+// set or branch is empty.
+func (c *Config) DefaultRemote(branch string) string {
+	if branch == "" {
+		return "origin"
+	}
+	if remote, ok := c.Get("branch." + strings.TrimPrefix(branch, "refs/heads/") + ".remote"); ok {
+		return remote
+	}
+	return "origin"
+}
+
+// RelativeURL resolves url, which starts with "./" or "../", against
+// remoteURL following git's relative_url: each leading "../" removes the
+// last path component of remoteURL, or its host separator from an
+// scp-like URL, as in "host:repo" and "../lib" giving "host:lib". A
+// relative remoteURL gives a relative result, which is prefixed with
+// upPath if it is not empty.
+func RelativeURL(remoteURL, url, upPath string) (string, error) {
+	if !isLocalPath(url) || filepath.IsAbs(url) {
+		return url, nil
+	}
+	if remoteURL == "" {
+		return "", errors.New("invalid empty remote URL")
+	}
+
+	remote := strings.TrimSuffix(remoteURL, "/")
+	relative := isLocalPath(remote) && !filepath.IsAbs(remote)
+	if relative && !strings.HasPrefix(remote, "./") && !strings.HasPrefix(remote, "../") {
+		remote = "./" + remote
+	}
+
+	colon := false
+	for {
+		if rest, ok := strings.CutPrefix(url, "../"); ok {
+			url = rest
+
+			// chop the last directory off of remote
+			if i := strings.LastIndexByte(remote, '/'); i >= 0 {
+				remote = remote[:i]
+			} else if i := strings.LastIndexByte(remote, ':'); i >= 0 {
+				remote, colon = remote[:i], true
+			} else if relative || remote == "." {
+				return "", fmt.Errorf("cannot strip one component off url '%s'", remote)
+			} else {
+				remote = "."
+			}
+		} else if rest, ok := strings.CutPrefix(url, "./"); ok {
+			url = rest
+		} else {
+			break
+		}
+	}
+
+	sep := "/"
+	if colon {
+		sep = ":"
+	}
+	out := remote + sep + url
+	if strings.HasSuffix(url, "/") {
+		out = out[:len(out)-1]
+	}
+	out = strings.TrimPrefix(out, "./")
+
+	if upPath == "" || !relative {
+		return out, nil
+	}
+	return upPath + out, nil
+}
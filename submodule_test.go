@@ -0,0 +1,175 @@
+package gitconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubmodules(t *testing.T) {
+	gitmodules, err := Parse([]byte(`[submodule "lib"]
+	path = vendor/lib
+	url = ../lib.git
+	branch = main
+	shallow = true
+[submodule "docs"]
+	path = docs
+	url = https://example.com/docs.git
+	update = rebase
+	ignore = dirty
+	fetchRecurseSubmodules = on-demand
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := Parse([]byte(`[submodule "lib"]
+	url = https://example.com/lib.git
+	path = elsewhere
+	update = !make
+[submodule "other"]
+	url = https://example.com/other.git
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{Layers: []*Layer{{Scope: ScopeLocal, Sections: local}}}
+
+	submodules, err := Submodules(gitmodules, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Submodule{
+		{Name: "lib", Path: "vendor/lib", URL: "https://example.com/lib.git", Branch: "main", Update: "!make", Shallow: true},
+		{Name: "docs", Path: "docs", URL: "https://example.com/docs.git", Update: "rebase", Ignore: "dirty", FetchRecurseSubmodules: "on-demand"},
+	}
+	if !reflect.DeepEqual(want, submodules) {
+		t.Errorf("want submodules %+v, got %+v", want, submodules)
+	}
+}
+
+func TestCheckGitmodules(t *testing.T) {
+	tests := []struct {
+		gitmodules string
+		want       string
+	}{
+		{"[submodule \"ok\"]\n\tpath = a\n\turl = ../a.git\n", ""},
+		{"[submodule \"ok\"]\n\turl = ./a/../b.git\n", ""},
+		{"[submodule \"..foo\"]\n\turl = git://example.com/a\n", ""},
+		{"[submodule]\n\turl = --upload-pack=evil\n", ""},
+		{"[submodule \"../../hooks\"]\n\tpath = a\n", "disallowed submodule name: ../../hooks"},
+		{"[submodule \"a/../b\"]\n\tpath = a\n", "disallowed submodule name: a/../b"},
+		{"[submodule \"a\\\\..\"]\n\tpath = a\n", "disallowed submodule name: a\\.."},
+		{"[submodule \"a\"]\n\turl = --upload-pack=evil\n", "disallowed submodule url: --upload-pack=evil"},
+		{"[submodule \"a\"]\n\tpath = -evil\n", "disallowed submodule path: -evil"},
+		{"[submodule \"a\"]\n\tupdate = !rm -rf /\n", "disallowed submodule update setting: !rm -rf /"},
+		{"[submodule \"a\"]\n\turl = ../../../../example.com/repo\n", ""},
+		{"[submodule \"a\"]\n\turl = ../../:example.com/repo\n", "disallowed submodule url: ../../:example.com/repo"},
+		{"[submodule \"a\"]\n\turl = ./..//example.com/repo\n", "disallowed submodule url: ./..//example.com/repo"},
+		{"[submodule \"a\"]\n\turl = ..\\\\..\\\\:evil\n", "disallowed submodule url: ..\\..\\:evil"},
+		{"[submodule \"a\"]\n\turl = .\\\\..\\\\/example.com/repo\n", "disallowed submodule url: .\\..\\/example.com/repo"},
+		{"[submodule \"a\"]\n\turl = ../a%0a.git\n", "disallowed submodule url: ../a%0a.git"},
+		{"[submodule \"a\"]\n\turl = https://example.com/a%0a.git\n", "disallowed submodule url: https://example.com/a%0a.git"},
+		{"[submodule \"a\"]\n\turl = https:///example.com/a.git\n", "disallowed submodule url: https:///example.com/a.git"},
+	}
+
+	for _, test := range tests {
+		sections, err := Parse([]byte(test.gitmodules))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = CheckGitmodules(sections)
+		switch {
+		case test.want == "" && err != nil:
+			t.Errorf("%q: want no error, got %v", test.gitmodules, err)
+		case test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)):
+			t.Errorf("%q: want error %q, got %v", test.gitmodules, test.want, err)
+		}
+	}
+
+	sections, _ := Parse([]byte("[submodule \"../x\"]\n\tpath = -x\n"))
+	if _, err := Submodules(sections, nil); err == nil || strings.Count(err.Error(), "\n") != 1 {
+		t.Errorf("want two joined errors, got %v", err)
+	}
+}
+
+func TestRelativeURL(t *testing.T) {
+	tests := []struct {
+		upPath, remote, url, want string
+	}{
+		{"../", "../foo", "../submodule", "../../submodule"},
+		{"../", "../foo/bar", "../submodule", "../../foo/submodule"},
+		{"../", "./foo/bar", "../submodule", "../foo/submodule"},
+		{"../../../", "../foo/bar", "../sub/a/b/c", "../../../../foo/sub/a/b/c"},
+		{"../", "/srv/addtest", "../repo", "/srv/repo"},
+		{"../", "foo", "../submodule", "../submodule"},
+		{"", "../foo/bar/", "../sub/a/b/c", "../foo/sub/a/b/c"},
+		{"", "./foo", "../submodule", "submodule"},
+		{"", "//somewhere else/repo", "../subrepo", "//somewhere else/subrepo"},
+		{"", "/srv/submodule_update_repo", "./.", "/srv/submodule_update_repo/."},
+		{"", "/srv/home2/../remote", "../bundle1", "/srv/home2/../bundle1"},
+		{"", "file:///tmp/repo", "../subrepo", "file:///tmp/subrepo"},
+		{"", "helper:://hostname/repo", "../subrepo", "helper:://hostname/subrepo"},
+		{"", "helper:://hostname/repo", "../../../subrepo", "helper::/subrepo"},
+		{"", "helper:://hostname/repo", "../../../../../subrepo", "helper:subrepo"},
+		{"", "helper:://hostname/repo", "../../../../../../subrepo", ".:subrepo"},
+		{"", "ssh://hostname/repo", "../../subrepo", "ssh://subrepo"},
+		{"", "ssh://hostname:22/repo", "../subrepo", "ssh://hostname:22/subrepo"},
+		{"", "user@host:repo", "../subrepo", "user@host:subrepo"},
+		{"", "user@host:path/to/repo", "../subrepo", "user@host:path/to/subrepo"},
+		{"", "user@host:~user/repo", "../subrepo", "user@host:~user/subrepo"},
+		{"", "https://example.com/org/repo", "https://other.example.com/lib", "https://other.example.com/lib"},
+		{"", "https://example.com/org/repo", "sub/", "https://example.com/org/repo/sub"},
+	}
+
+	for _, test := range tests {
+		got, err := RelativeURL(test.remote, test.url, test.upPath)
+		if err != nil {
+			t.Errorf("%s against %s: %v", test.url, test.remote, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s against %s: want %q, got %q", test.url, test.remote, test.want, got)
+		}
+	}
+
+	if _, err := RelativeURL("foo", "../../submodule", ""); err == nil || err.Error() != "cannot strip one component off url '.'" {
+		t.Errorf("want strip error, got %v", err)
+	}
+}
+
+func TestResolveSubmoduleURL(t *testing.T) {
+	sections, err := Parse([]byte(`[remote "origin"]
+	url = git@github.com:benburkert/superproject.git
+[remote "fork"]
+	url = https://example.com/fork/superproject
+[branch "topic"]
+	remote = fork
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{Layers: []*Layer{{Sections: sections}}}
+
+	tests := []struct {
+		conf              *Config
+		url, branch, want string
+	}{
+		{conf, "../lib.git", "main", "git@github.com:benburkert/lib.git"},
+		{conf, "../lib.git", "", "git@github.com:benburkert/lib.git"},
+		{conf, "../lib", "topic", "https://example.com/fork/lib"},
+		{conf, "./lib", "refs/heads/topic", "https://example.com/fork/superproject/lib"},
+		{conf, "https://example.com/lib", "main", "https://example.com/lib"},
+		{&Config{}, "../lib", "main", "/srv/lib"},
+	}
+	for _, test := range tests {
+		got, err := test.conf.ResolveSubmoduleURL(test.url, test.branch, "/srv/superproject")
+		if err != nil {
+			t.Errorf("%s on %q: %v", test.url, test.branch, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s on %q: want %q, got %q", test.url, test.branch, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package gitconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// A URL is a parsed git remote URL.
+type URL struct {
+	// Transport is how the repository is reached: "local" for a path,
+	// "ssh" for scp-like and ssh:// URLs, "file", "git", "http" and
+	// "https", or the name of a remote helper for "<helper>::<address>"
+	// and other URL schemes.
+	Transport string
+
+	User, Host, Port string
+
+	// Path is the path of the repository. A leading "/~" of ssh and git
+	// URLs is stripped to leave "~user/..." or "~/...". For a remote
+	// helper, Path is the address handed to the helper.
+	Path string
+}
+
+// ParseURL parses a remote URL the way git detects transports:
+//
+//	<helper>::<address>               remote helper
+//	<scheme>://[user@]host[:port]/path URL
+//	[user@]host:path                  scp-like ssh
+//	path                              local
+//
+// An scp-like URL is only recognized if there is no slash before the
+// first colon, so "./a:b" is a local path. Host names and ssh paths that
+// start with "-" are rejected, as they would be taken for options.
+func ParseURL(raw string) (*URL, error) {
+	if helper, address, ok := remoteHelper(raw); ok {
+		return &URL{Transport: helper, Path: address}, nil
+	}
+
+	if isURL(raw) {
+		return parseSchemeURL(raw)
+	}
+	if isLocalPath(raw) {
+		return &URL{Transport: "local", Path: raw}, nil
+	}
+	return parseSCPURL(raw)
+}
+
+// parseSchemeURL parses a "<scheme>://..." URL.
+func parseSchemeURL(raw string) (*URL, error) {
+	scheme, rest, _ := strings.Cut(raw, "://")
+	u := &URL{Transport: strings.ToLower(scheme)}
+	switch u.Transport {
+	case "git+ssh", "ssh+git":
+		u.Transport = "ssh"
+	}
+
+	authority, path := rest, ""
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		authority, path = rest[:i], rest[i:]
+	}
+
+	if i := strings.LastIndexByte(authority, '@'); i >= 0 {
+		u.User, authority = authority[:i], authority[i+1:]
+	}
+	host, port, err := splitHostPort(authority)
+	if err != nil {
+		return nil, err
+	}
+	u.Host, u.Port = host, port
+
+	u.User, u.Host, u.Path = urlDecode(u.User), urlDecode(u.Host), urlDecode(path)
+
+	switch u.Transport {
+	case "ssh", "git":
+		if strings.HasPrefix(u.Path, "/~") {
+			u.Path = u.Path[1:]
+		}
+	}
+	if u.Transport == "ssh" {
+		return u, checkSSH(u)
+	}
+	return u, nil
+}
+
+// parseSCPURL parses an scp-like "[user@]host:path" URL. The host may be
+// wrapped in brackets, as in "[user@host:port]:path" or "[::1]:path".
+func parseSCPURL(raw string) (*URL, error) {
+	u := &URL{Transport: "ssh"}
+
+	authority, path := raw, ""
+	if strings.HasPrefix(raw, "[") {
+		end := strings.IndexByte(raw, ']')
+		if end < 0 || end+1 >= len(raw) || raw[end+1] != ':' {
+			return nil, fmt.Errorf("invalid URL '%s'", raw)
+		}
+		authority, path = raw[1:end], raw[end+2:]
+
+		if i := strings.LastIndexByte(authority, '@'); i >= 0 {
+			u.User, authority = authority[:i], authority[i+1:]
+		}
+		if strings.Count(authority, ":") == 1 {
+			u.Host, u.Port, _ = strings.Cut(authority, ":")
+		} else {
+			u.Host = authority
+		}
+	} else {
+		authority, path, _ = strings.Cut(raw, ":")
+		if i := strings.LastIndexByte(authority, '@'); i >= 0 {
+			u.User, authority = authority[:i], authority[i+1:]
+		}
+		u.Host = authority
+	}
+
+	u.Path = path
+	if strings.HasPrefix(u.Path, "/~") {
+		u.Path = u.Path[1:]
+	}
+	return u, checkSSH(u)
+}
+
+// splitHostPort splits "host:port" or "[host]:port".
+func splitHostPort(authority string) (host, port string, err error) {
+	if strings.HasPrefix(authority, "[") {
+		end := strings.IndexByte(authority, ']')
+		if end < 0 {
+			return "", "", fmt.Errorf("invalid host '%s'", authority)
+		}
+		host, rest := authority[1:end], authority[end+1:]
+		if rest == "" {
+			return host, "", nil
+		}
+		if rest[0] != ':' {
+			return "", "", fmt.Errorf("invalid host '%s'", authority)
+		}
+		return host, rest[1:], checkPort(rest[1:])
+	}
+
+	host, port, _ = strings.Cut(authority, ":")
+	return host, port, checkPort(port)
+}
+
+func checkPort(port string) error {
+	for i := 0; i < len(port); i++ {
+		if !isDigit(port[i]) {
+			return fmt.Errorf("strange port '%s' blocked", port)
+		}
+	}
+	return nil
+}
+
+// checkSSH rejects hosts, users and paths that ssh would take for options.
+func checkSSH(u *URL) error {
+	switch {
+	case strings.HasPrefix(u.Host, "-"):
+		return fmt.Errorf("strange hostname '%s' blocked", u.Host)
+	case strings.HasPrefix(u.User, "-"):
+		return fmt.Errorf("strange username '%s' blocked", u.User)
+	case strings.HasPrefix(u.Port, "-"):
+		return fmt.Errorf("strange port '%s' blocked", u.Port)
+	case strings.HasPrefix(u.Path, "-"):
+		return fmt.Errorf("strange pathname '%s' blocked", u.Path)
+	}
+	return nil
+}
+
+// remoteHelper splits a "<helper>::<address>" URL.
+func remoteHelper(raw string) (helper, address string, ok bool) {
+	i := 0
+	for i < len(raw) && isURLSchemeChar(i == 0, raw[i]) {
+		i++
+	}
+	if i == 0 || !strings.HasPrefix(raw[i:], "::") {
+		return "", "", false
+	}
+	return raw[:i], raw[i+2:], true
+}
+
+// isURL reports whether raw starts with "<scheme>://".
+func isURL(raw string) bool {
+	i := 0
+	for i < len(raw) && raw[i] != ':' {
+		if !isURLSchemeChar(i == 0, raw[i]) {
+			return false
+		}
+		i++
+	}
+	return i > 0 && strings.HasPrefix(raw[i:], "://")
+}
+
+// isURLSchemeChar is git's looser version of RFC 3986 scheme characters,
+// [A-Za-z0-9][A-Za-z0-9+.-]*.
+func isURLSchemeChar(first bool, c byte) bool {
+	if isAlpha(c) || isDigit(c) {
+		return true
+	}
+	return !first && (c == '+' || c == '-' || c == '.')
+}
+
+// isLocalPath reports whether raw is a path rather than an scp-like URL:
+// it has no colon, a slash before its first colon, or a drive letter.
+func isLocalPath(raw string) bool {
+	colon := strings.IndexByte(raw, ':')
+	slash := strings.IndexByte(raw, '/')
+	return colon < 0 || (slash >= 0 && slash < colon) || filepath.VolumeName(raw) != ""
+}
+
+// urlDecode decodes the %XX escapes of s, leaving invalid escapes as they
+// are, like git's url_decode.
+func urlDecode(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case isDigit(c):
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
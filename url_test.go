@@ -0,0 +1,59 @@
+package gitconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want *URL
+	}{
+		{"git@github.com:benburkert/go-gitconfig.git", &URL{Transport: "ssh", User: "git", Host: "github.com", Path: "benburkert/go-gitconfig.git"}},
+		{"host:/srv/repo", &URL{Transport: "ssh", Host: "host", Path: "/srv/repo"}},
+		{"host:~/repo", &URL{Transport: "ssh", Host: "host", Path: "~/repo"}},
+		{"[git@host:2222]:repo", &URL{Transport: "ssh", User: "git", Host: "host", Port: "2222", Path: "repo"}},
+		{"[::1]:repo", &URL{Transport: "ssh", Host: "::1", Path: "repo"}},
+		{"ssh://user@host:2222/path/repo", &URL{Transport: "ssh", User: "user", Host: "host", Port: "2222", Path: "/path/repo"}},
+		{"ssh://host/~user/repo", &URL{Transport: "ssh", Host: "host", Path: "~user/repo"}},
+		{"git+ssh://[::1]:22/repo", &URL{Transport: "ssh", Host: "::1", Port: "22", Path: "/repo"}},
+		{"git://example.com/~/repo", &URL{Transport: "git", Host: "example.com", Path: "~/repo"}},
+		{"https://user%40corp@example.com/a%20b.git", &URL{Transport: "https", User: "user@corp", Host: "example.com", Path: "/a b.git"}},
+		{"HTTP://example.com", &URL{Transport: "http", Host: "example.com"}},
+		{"file:///srv/repo", &URL{Transport: "file", Path: "/srv/repo"}},
+		{"../sibling", &URL{Transport: "local", Path: "../sibling"}},
+		{"/srv/repo", &URL{Transport: "local", Path: "/srv/repo"}},
+		{"repo", &URL{Transport: "local", Path: "repo"}},
+		{"./a:b", &URL{Transport: "local", Path: "./a:b"}},
+		{"a/b:c", &URL{Transport: "local", Path: "a/b:c"}},
+		{"ext::ssh -i key host %S repo", &URL{Transport: "ext", Path: "ssh -i key host %S repo"}},
+		{"persistent-https://example.com/repo", &URL{Transport: "persistent-https", Host: "example.com", Path: "/repo"}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseURL(test.raw)
+		if err != nil {
+			t.Errorf("%s: %v", test.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("%s: want %+v, got %+v", test.raw, test.want, got)
+		}
+	}
+}
+
+func TestParseURLErrors(t *testing.T) {
+	tests := map[string]string{
+		"-oProxyCommand=evil:repo":    "strange hostname '-oProxyCommand=evil' blocked",
+		"ssh://-oProxyCommand=evil/r": "strange hostname '-oProxyCommand=evil' blocked",
+		"host:-repo":                  "strange pathname '-repo' blocked",
+		"ssh://host:22x/repo":         "strange port '22x' blocked",
+		"[host:repo":                  "invalid URL '[host:repo'",
+	}
+	for raw, want := range tests {
+		if _, err := ParseURL(raw); err == nil || err.Error() != want {
+			t.Errorf("%s: want error %q, got %v", raw, want, err)
+		}
+	}
+}
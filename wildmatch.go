@@ -0,0 +1,240 @@
+package gitconfig
+
+import "strings"
+
+// wildmatch flags.
+const (
+	wmCaseFold = 1 << iota // match case insensitively
+	wmPathname             // "*" and "?" do not match "/", "**" does
+)
+
+// wildmatch results.
+const (
+	wmMatch           = 0
+	wmNoMatch         = 1
+	wmAbortAll        = -1
+	wmAbortToStarStar = -2
+)
+
+// wildmatch reports whether text matches the shell glob pattern, following
+// the rules of git's wildmatch: with wmPathname, "*" matches within one
+// path component, "**/" matches zero or more directories, and "/**" at
+// the end matches everything below a directory.
+func wildmatch(pattern, text string, flags int) bool {
+	return dowild(pattern, text, flags) == wmMatch
+}
+
+func dowild(pattern, text string, flags int) int {
+	// at returns the byte at i, or 0 past the end like a C string.
+	at := func(s string, i int) byte {
+		if i < len(s) {
+			return s[i]
+		}
+		return 0
+	}
+	fold := func(c byte) byte {
+		if flags&wmCaseFold != 0 && 'A' <= c && c <= 'Z' {
+			return c + 'a' - 'A'
+		}
+		return c
+	}
+
+	p, t := 0, 0
+	for ; p < len(pattern); t, p = t+1, p+1 {
+		pch, tch := fold(pattern[p]), fold(at(text, t))
+		if tch == 0 && pch != '*' {
+			return wmAbortAll
+		}
+
+		switch pch {
+		case '\\':
+			// literal match with the following character
+			p++
+			if fold(at(pattern, p)) != tch {
+				return wmNoMatch
+			}
+		default:
+			if pch != tch {
+				return wmNoMatch
+			}
+		case '?':
+			if flags&wmPathname != 0 && tch == '/' {
+				return wmNoMatch
+			}
+		case '*':
+			var matchSlash bool
+			if p++; at(pattern, p) == '*' {
+				prev := p - 2
+				for p++; at(pattern, p) == '*'; p++ {
+				}
+				if (prev < 0 || pattern[prev] == '/') &&
+					(at(pattern, p) == 0 || at(pattern, p) == '/' ||
+						(at(pattern, p) == '\\' && at(pattern, p+1) == '/')) {
+					// "**/" matches zero or more directories, so try
+					// matching the rest of the pattern here first.
+					if at(pattern, p) == '/' && dowild(pattern[p+1:], text[t:], flags) == wmMatch {
+						return wmMatch
+					}
+					matchSlash = true
+				}
+			} else {
+				// without wmPathname, "*" is the same as "**"
+				matchSlash = flags&wmPathname == 0
+			}
+
+			if p == len(pattern) {
+				// a trailing "**" matches everything, and a trailing
+				// "*" only if there are no more slashes.
+				if !matchSlash && strings.IndexByte(text[t:], '/') >= 0 {
+					return wmNoMatch
+				}
+				return wmMatch
+			} else if !matchSlash && pattern[p] == '/' {
+				// a single "*" followed by a slash matches the next
+				// directory.
+				slash := strings.IndexByte(text[t:], '/')
+				if slash < 0 {
+					return wmNoMatch
+				}
+				t += slash
+				break
+			}
+
+			for {
+				if tch == 0 {
+					break
+				}
+
+				// skip ahead to the next occurrence of a literal that
+				// follows the asterisk.
+				if c := pattern[p]; !strings.ContainsRune("*?[\\", rune(c)) {
+					pch := fold(c)
+					for tch = fold(at(text, t)); tch != 0 && (matchSlash || tch != '/'); tch = fold(at(text, t)) {
+						if tch == pch {
+							break
+						}
+						t++
+					}
+					if tch != pch {
+						if matchSlash {
+							return wmAbortAll
+						}
+						return wmAbortToStarStar
+					}
+				}
+
+				if matched := dowild(pattern[p:], text[t:], flags); matched != wmNoMatch {
+					if !matchSlash || matched != wmAbortToStarStar {
+						return matched
+					}
+				} else if !matchSlash && tch == '/' {
+					return wmAbortToStarStar
+				}
+				t++
+				tch = fold(at(text, t))
+			}
+			return wmAbortAll
+		case '[':
+			p++
+			pch = at(pattern, p)
+			negated := pch == '!' || pch == '^'
+			if negated {
+				p++
+				pch = at(pattern, p)
+			}
+
+			var prev byte
+			matched := false
+			for {
+				switch {
+				case pch == 0:
+					return wmAbortAll
+				case pch == '\\':
+					p++
+					if pch = at(pattern, p); pch == 0 {
+						return wmAbortAll
+					}
+					if tch == pch {
+						matched = true
+					}
+				case pch == '-' && prev != 0 && at(pattern, p+1) != 0 && at(pattern, p+1) != ']':
+					p++
+					if pch = at(pattern, p); pch == '\\' {
+						p++
+						if pch = at(pattern, p); pch == 0 {
+							return wmAbortAll
+						}
+					}
+					if prev <= tch && tch <= pch {
+						matched = true
+					} else if flags&wmCaseFold != 0 && 'a' <= tch && tch <= 'z' {
+						if upper := tch - 'a' + 'A'; prev <= upper && upper <= pch {
+							matched = true
+						}
+					}
+					pch = 0 // resets prev
+				case pch == '[' && at(pattern, p+1) == ':':
+					start := p + 2
+					end := strings.IndexByte(pattern[start:], ']')
+					if end < 0 {
+						return wmAbortAll
+					}
+					end += start
+					if end-start < 1 || pattern[end-1] != ':' {
+						// no ":]", so treat it like a normal set.
+						if tch == '[' {
+							matched = true
+						}
+						break
+					}
+
+					class, ok := charClasses[pattern[start:end-1]]
+					if !ok {
+						return wmAbortAll
+					}
+					if class(tch) {
+						matched = true
+					}
+					p, pch = end, 0
+				default:
+					if tch == pch {
+						matched = true
+					}
+				}
+
+				prev = pch
+				p++
+				if pch = at(pattern, p); pch == ']' {
+					break
+				}
+			}
+			if matched == negated || (flags&wmPathname != 0 && tch == '/') {
+				return wmNoMatch
+			}
+		}
+	}
+
+	if t < len(text) {
+		return wmNoMatch
+	}
+	return wmMatch
+}
+
+var charClasses = map[string]func(c byte) bool{
+	"alnum":  func(c byte) bool { return isAlpha(c) || isDigit(c) },
+	"alpha":  isAlpha,
+	"blank":  func(c byte) bool { return c == ' ' || c == '\t' },
+	"cntrl":  func(c byte) bool { return c < 0x20 || c == 0x7f },
+	"digit":  isDigit,
+	"graph":  func(c byte) bool { return 0x21 <= c && c <= 0x7e },
+	"lower":  func(c byte) bool { return 'a' <= c && c <= 'z' },
+	"print":  func(c byte) bool { return 0x20 <= c && c <= 0x7e },
+	"punct":  func(c byte) bool { return 0x21 <= c && c <= 0x7e && !isAlpha(c) && !isDigit(c) },
+	"space":  func(c byte) bool { return c == ' ' || ('\t' <= c && c <= '\r') },
+	"upper":  func(c byte) bool { return 'A' <= c && c <= 'Z' },
+	"xdigit": func(c byte) bool { return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F') },
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,45 @@
+package gitconfig
+
+import "testing"
+
+func TestWildmatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		flags         int
+		want          bool
+	}{
+		{"foo", "foo", wmPathname, true},
+		{"foo", "bar", wmPathname, false},
+		{"???", "foo", wmPathname, true},
+		{"*", "foo/bar", wmPathname, false},
+		{"*", "foo/bar", 0, true},
+		{"foo/*", "foo/bar", wmPathname, true},
+		{"foo/*/baz", "foo/bar/baz", wmPathname, true},
+		{"foo/*/baz", "foo/bar/qux/baz", wmPathname, false},
+		{"**/foo", "foo", wmPathname, true},
+		{"**/foo", "a/b/foo", wmPathname, true},
+		{"**/foo", "a/bfoo", wmPathname, false},
+		{"foo/**", "foo/a/b", wmPathname, true},
+		{"foo/**/bar", "foo/bar", wmPathname, true},
+		{"foo/**/bar", "foo/a/b/bar", wmPathname, true},
+		{"foo**bar", "foo/bar", wmPathname, false},
+		{"*.git", "repo.git", wmPathname, true},
+		{"[a-c]x", "bx", wmPathname, true},
+		{"[!a-c]x", "bx", wmPathname, false},
+		{"[^a-c]x", "dx", wmPathname, true},
+		{"[[:digit:]]", "7", wmPathname, true},
+		{"[[:digit:]]", "a", wmPathname, false},
+		{"[[:bogus:]]", "a", wmPathname, false},
+		{`\*`, "*", wmPathname, true},
+		{`\*`, "a", wmPathname, false},
+		{"FOO/**", "foo/bar", wmPathname, false},
+		{"FOO/**", "foo/bar", wmPathname | wmCaseFold, true},
+		{"[A-C]", "b", wmPathname | wmCaseFold, true},
+	}
+
+	for _, test := range tests {
+		if got := wildmatch(test.pattern, test.text, test.flags); got != test.want {
+			t.Errorf("want wildmatch(%q, %q) = %t, got %t", test.pattern, test.text, test.want, got)
+		}
+	}
+}
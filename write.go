@@ -0,0 +1,193 @@
+package gitconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxSymlinkDepth is the number of symlinks git follows when locking a
+// file for writing.
+const maxSymlinkDepth = 5
+
+var errSymlinkDepth = errors.New("too many levels of symbolic links")
+
+// Marshal returns the git config file encoding of sections. Keys are
+// written in sorted order, and values are quoted where git would need
+// them to be. Sections and keys that Parse would not read back as they
+// are, such as names with upper case letters or subsections with line
+// breaks, are an error.
+func Marshal(sections []*Section) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, s := range sections {
+		if !isIdentifier(s.Type) || strings.Contains(s.Type, ".") {
+			return nil, fmt.Errorf("invalid section name: %s", s.Type)
+		}
+		if strings.ContainsAny(s.ID, "\r\n") {
+			return nil, fmt.Errorf("invalid subsection name: %q", s.ID)
+		}
+
+		buf.WriteString("[" + s.Type)
+		if s.ID != "" {
+			buf.WriteString(` "` + escapeID(s.ID) + `"`)
+		}
+		buf.WriteString("]\n")
+
+		keys := make([]string, 0, len(s.Values))
+		for key := range s.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if !isIdentifier(key) {
+				return nil, fmt.Errorf("invalid key: %s", key)
+			}
+			buf.WriteString("\t" + key + " = " + quote(s.Values[key]) + "\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// isIdentifier reports whether name is a section name or key as Parse
+// returns it: not empty, in lower case, and made of letters, digits and
+// "_-@.".
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; (c < 'a' || c > 'z') && !isDigit(c) && strings.IndexByte("_-@.", c) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteFile writes data to the named config file, creating it with perm
+// (before the umask) if it does not exist. Like git, the new contents are
+// written to a "<name>.lock" file which is renamed over the original.
+// Symlinks are followed so that the file they point to is replaced
+// instead of the link, and the mode of an existing file is kept, as is
+// its owner where the process may change it. Data that does not parse is
+// refused, so that a broken file never replaces a working one.
+func WriteFile(name string, data []byte, perm os.FileMode) error {
+	if _, err := Parse(data); err != nil {
+		return err
+	}
+
+	path, err := resolveSymlink(name)
+	if err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(path)
+	switch {
+	case err == nil:
+		perm = fi.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	case os.IsNotExist(err):
+		fi = nil
+	default:
+		return err
+	}
+
+	lock := path + ".lock"
+	f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if err := writeLocked(f, data, perm, fi); err != nil {
+		f.Close()
+		os.Remove(lock)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(lock)
+		return err
+	}
+	if err := os.Rename(lock, path); err != nil {
+		os.Remove(lock)
+		return err
+	}
+	return nil
+}
+
+// chown changes the owner of a rewritten file. It is a variable for tests.
+var chown = (*os.File).Chown
+
+func writeLocked(f *os.File, data []byte, perm os.FileMode, orig os.FileInfo) error {
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+
+	// a new file keeps the mode the umask gave it.
+	if orig == nil {
+		return nil
+	}
+
+	// the umask applied to the lock file too, so restore the mode of the
+	// file it replaces.
+	if err := f.Chmod(perm); err != nil {
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	uid, gid, ok := fileOwner(orig)
+	if !ok {
+		return nil
+	}
+	if nuid, ngid, _ := fileOwner(fi); nuid == uid && ngid == gid {
+		return nil
+	}
+
+	// git never changes owners, so keeping the owner is best effort: only
+	// root may give a file away, as when editing another user's
+	// group-writable config.
+	chown(f, uid, gid)
+	return nil
+}
+
+// resolveSymlink follows the chain of symlinks starting at name and
+// returns the path of the file at the end of it, which may not exist yet.
+func resolveSymlink(name string) (string, error) {
+	path := name
+	for depth := 0; ; depth++ {
+		link, err := os.Readlink(path)
+		if err != nil {
+			if fi, lerr := os.Lstat(path); lerr == nil && fi.Mode()&os.ModeSymlink != 0 {
+				return "", err
+			}
+			return path, nil
+		}
+		if depth == maxSymlinkDepth {
+			return "", &os.PathError{Op: "resolve", Path: name, Err: errSymlinkDepth}
+		}
+
+		if filepath.IsAbs(link) {
+			path = link
+		} else {
+			path = filepath.Join(filepath.Dir(path), link)
+		}
+	}
+}
+
+func escapeID(id string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(id)
+}
+
+// quote encodes value so that Parse decodes it unchanged. Like git, which
+// has no escape for it, a carriage return is kept inside double quotes.
+func quote(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\b", `\b`).Replace(value)
+	if value == "" || value != strings.TrimSpace(value) || strings.ContainsAny(value, "#;\r") {
+		return `"` + value + `"`
+	}
+	return value
+}
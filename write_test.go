@@ -0,0 +1,209 @@
+package gitconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	want, err := Parse(configData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want sections %#v, got %#v", want, got)
+	}
+}
+
+func TestMarshalQuoting(t *testing.T) {
+	values := map[string]string{
+		"comment":   "a # b ; c",
+		"space":     "  padded ",
+		"quote":     `say "hi"`,
+		"backslash": `C:\path\to`,
+		"control":   "tab\tnewline\n",
+		"return":    "cr\rcrlf\r\n",
+		"empty":     "",
+	}
+
+	want := []*Section{{Type: "test", ID: `a "quoted" \ id`, Values: values}}
+	data, err := Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want sections %#v, got %#v", want, got)
+	}
+}
+
+func TestMarshalInvalid(t *testing.T) {
+	tests := []struct {
+		section *Section
+		err     string
+	}{
+		{&Section{Type: "Core"}, "invalid section name: Core"},
+		{&Section{Type: "a.b"}, "invalid section name: a.b"},
+		{&Section{Type: ""}, "invalid section name: "},
+		{&Section{Type: "a b"}, "invalid section name: a b"},
+		{&Section{Type: "branch", ID: "a\nb"}, `invalid subsection name: "a\nb"`},
+		{&Section{Type: "branch", ID: "a\rb"}, `invalid subsection name: "a\rb"`},
+		{&Section{Type: "core", Values: map[string]string{"Editor": "vim"}}, "invalid key: Editor"},
+		{&Section{Type: "core", Values: map[string]string{"a key": "vim"}}, "invalid key: a key"},
+		{&Section{Type: "core", Values: map[string]string{"": "vim"}}, "invalid key: "},
+	}
+
+	for _, test := range tests {
+		data, err := Marshal([]*Section{test.section})
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%+v: want error %q, got %q, %v", test.section, test.err, data, err)
+		}
+	}
+}
+
+func TestWriteFileSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "gitconfig")
+	if err := os.Mkdir(filepath.Dir(target), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("[user]\n\tname = old\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(target, 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	link := filepath.Join(dir, ".gitconfig")
+	if err := os.Symlink("dotfiles/gitconfig", link); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("[user]\n\tname = new\n")
+	if err := WriteFile(link, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(link, []byte("[user\n"), 0666); err == nil || err.Error() != "bad config line 1" {
+		t.Errorf("want invalid data refused, got %v", err)
+	}
+
+	if fi, err := os.Lstat(link); err != nil {
+		t.Fatal(err)
+	} else if fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("want %s to remain a symlink, got mode %v", link, fi.Mode())
+	}
+
+	fi, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := os.FileMode(0640), fi.Mode().Perm(); want != got {
+		t.Errorf("want mode %v, got %v", want, got)
+	}
+
+	got, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := string(data); want != string(got) {
+		t.Errorf("want contents %q, got %q", want, got)
+	}
+
+	if _, err := os.Stat(target + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("want lock file removed, got %v", err)
+	}
+}
+
+func TestWriteFileSymlinkLoop(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := os.Symlink("b", a); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("a", b); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(a, []byte("[core]\n"), 0666); err == nil {
+		t.Error("want error writing through a symlink loop, got nil")
+	}
+
+	if fi, err := os.Lstat(a); err != nil {
+		t.Fatal(err)
+	} else if fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("want %s to remain a symlink, got mode %v", a, fi.Mode())
+	}
+}
+
+func TestWriteFileLocked(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(name+".lock", nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(name, []byte("[core]\n"), 0666); err == nil {
+		t.Error("want error writing a locked file, got nil")
+	}
+}
+
+func TestWriteFileUmask(t *testing.T) {
+	dir := t.TempDir()
+	name, ref := filepath.Join(dir, "config"), filepath.Join(dir, "ref")
+
+	if err := WriteFile(name, []byte("[core]\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ref, []byte("[core]\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rfi, err := os.Stat(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := rfi.Mode().Perm(), fi.Mode().Perm(); want != got {
+		t.Errorf("want mode %v of os.WriteFile, got %v", want, got)
+	}
+}
+
+func TestWriteFileOtherOwner(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(name, []byte("[user]\n\tname = old\n"), 0664); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(name, 12345, 12345); err != nil {
+		t.Skipf("cannot give the file to another user: %v", err)
+	}
+
+	defer func(orig func(*os.File, int, int) error) { chown = orig }(chown)
+	chown = func(*os.File, int, int) error { return os.ErrPermission }
+
+	data := []byte("[user]\n\tname = new\n")
+	if err := WriteFile(name, data, 0666); err != nil {
+		t.Fatalf("want the owner kept only if possible, got %v", err)
+	}
+	if got, err := os.ReadFile(name); err != nil || string(got) != string(data) {
+		t.Errorf("want contents %q, got %q, %v", data, got, err)
+	}
+}